@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// apiKeyConfig is one entry of API_KEYS_FILE. Keys are stored as the
+// hex-encoded SHA-256 of the secret, so the file never holds usable
+// credentials. Mint caps are in whole tokens; empty means unlimited.
+type apiKeyConfig struct {
+	Name              string  `json:"name"`
+	KeyHash           string  `json:"keyHash"`
+	RateLimit         float64 `json:"rateLimit,omitempty"`
+	RateBurst         int     `json:"rateBurst,omitempty"`
+	MaxMintPerRequest string  `json:"maxMintPerRequest,omitempty"`
+	MaxMintPerDay     string  `json:"maxMintPerDay,omitempty"`
+}
+
+type apiKey struct {
+	name          string
+	rate          *tokenBucket
+	maxPerRequest *big.Int
+	maxPerDay     *big.Int
+
+	// Daily usage is kept in memory, so a restart resets the day's total.
+	mu      sync.Mutex
+	day     string
+	usedDay *big.Int
+}
+
+type apiKeyContextKey struct{}
+
+// apiKeys maps a key hash to its settings. Authentication is disabled while
+// it is nil.
+var apiKeys map[[sha256.Size]byte]*apiKey
+
+func loadAPIKeys() error {
+	path := os.Getenv("API_KEYS_FILE")
+	if path == "" {
+		log.Println("Warning: API_KEYS_FILE not set - API key authentication is disabled")
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read API keys file: %v", err)
+	}
+	var entries []apiKeyConfig
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse API keys file: %v", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("API keys file %s has no keys", path)
+	}
+
+	apiKeys = make(map[[sha256.Size]byte]*apiKey, len(entries))
+	for i, entry := range entries {
+		key, hash, err := parseAPIKeyConfig(entry)
+		if err != nil {
+			return fmt.Errorf("API key %d: %v", i, err)
+		}
+		if _, dup := apiKeys[hash]; dup {
+			return fmt.Errorf("API key %d (%s): duplicate key hash", i, entry.Name)
+		}
+		apiKeys[hash] = key
+	}
+	return nil
+}
+
+func parseAPIKeyConfig(entry apiKeyConfig) (*apiKey, [sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	if entry.Name == "" {
+		return nil, hash, fmt.Errorf("name is required")
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(entry.KeyHash, "0x"))
+	if err != nil || len(raw) != sha256.Size {
+		return nil, hash, fmt.Errorf("keyHash must be a hex-encoded SHA-256 digest")
+	}
+	copy(hash[:], raw)
+
+	if entry.RateLimit < 0 || entry.RateBurst < 0 {
+		return nil, hash, fmt.Errorf("rateLimit and rateBurst must not be negative")
+	}
+	burst := entry.RateBurst
+	if entry.RateLimit > 0 && burst == 0 {
+		burst = max(1, int(entry.RateLimit))
+	}
+
+	key := &apiKey{name: entry.Name, rate: newTokenBucket(entry.RateLimit, burst), usedDay: new(big.Int)}
+	if key.maxPerRequest, err = parseMintCap(entry.MaxMintPerRequest); err != nil {
+		return nil, hash, fmt.Errorf("invalid maxMintPerRequest: %v", err)
+	}
+	if key.maxPerDay, err = parseMintCap(entry.MaxMintPerDay); err != nil {
+		return nil, hash, fmt.Errorf("invalid maxMintPerDay: %v", err)
+	}
+	return key, hash, nil
+}
+
+func parseMintCap(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := parseUnits(value, tokenDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("must be positive")
+	}
+	return amount, nil
+}
+
+// authMiddleware requires "Authorization: Bearer <key>" on every route
+// except the health check and applies the key's rate limit.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKeys == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() == routeHealth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		key := apiKeys[sha256.Sum256([]byte(strings.TrimSpace(secret)))]
+		if !ok || key == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondWithError(w, http.StatusUnauthorized, "Missing or invalid API key")
+			return
+		}
+
+		if !key.rate.allow() {
+			w.Header().Set("Retry-After", strconv.Itoa(key.rate.retryAfter()))
+			respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded for this API key")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
+
+// reserveMint checks amount against the caller's per-request and per-day
+// caps and counts it towards the day's total. It returns a message for the
+// caller when the mint is over a cap; releaseMint gives the amount back when
+// the mint was never broadcast.
+func reserveMint(ctx context.Context, amount *big.Int) string {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*apiKey)
+	if key == nil {
+		return ""
+	}
+	if key.maxPerRequest != nil && amount.Cmp(key.maxPerRequest) > 0 {
+		return fmt.Sprintf("Mint amount exceeds the per-request cap of %s tokens for this API key", formatUnits(key.maxPerRequest, tokenDecimals))
+	}
+	if key.maxPerDay == nil {
+		return ""
+	}
+
+	key.mu.Lock()
+	defer key.mu.Unlock()
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	if key.day != today {
+		key.day = today
+		key.usedDay.SetInt64(0)
+	}
+	total := new(big.Int).Add(key.usedDay, amount)
+	if total.Cmp(key.maxPerDay) > 0 {
+		remaining := new(big.Int).Sub(key.maxPerDay, key.usedDay)
+		return fmt.Sprintf("Mint amount exceeds the remaining daily cap of %s tokens for this API key", formatUnits(remaining, tokenDecimals))
+	}
+	key.usedDay = total
+	return ""
+}
+
+func releaseMint(ctx context.Context, amount *big.Int) {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*apiKey)
+	if key == nil || key.maxPerDay == nil {
+		return
+	}
+
+	key.mu.Lock()
+	defer key.mu.Unlock()
+	if key.day == time.Now().UTC().Format(time.DateOnly) {
+		key.usedDay.Sub(key.usedDay, amount)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+)
+
+type balanceAlertConfig struct {
+	threshold  *big.Int
+	webhookURL string
+	interval   time.Duration
+}
+
+type balanceAlertPayload struct {
+	Account   string `json:"account"`
+	Balance   string `json:"balance"`
+	Threshold string `json:"threshold"`
+	Message   string `json:"message"`
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+func loadBalanceAlertConfig() (*balanceAlertConfig, error) {
+	thresholdEth := os.Getenv("LOW_BALANCE_THRESHOLD_ETH")
+	if thresholdEth == "" {
+		return nil, nil
+	}
+
+	threshold, err := parseUnits(thresholdEth, 18)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOW_BALANCE_THRESHOLD_ETH: %v", err)
+	}
+
+	interval, err := envDuration("LOW_BALANCE_CHECK_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("LOW_BALANCE_CHECK_INTERVAL must be positive")
+	}
+
+	return &balanceAlertConfig{
+		threshold:  threshold,
+		webhookURL: os.Getenv("LOW_BALANCE_WEBHOOK_URL"),
+		interval:   interval,
+	}, nil
+}
+
+// monitorDeployerBalance alerts once each time the deployer's balance drops
+// below the threshold, and re-arms once the balance has been topped up.
+func monitorDeployerBalance(cfg *balanceAlertConfig) {
+	alerted := false
+	ticker := time.NewTicker(cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		balance, err := client.BalanceAt(context.Background(), fromAddress, nil)
+		if err != nil {
+			log.Printf("Balance monitor: failed to read deployer balance: %v", err)
+		} else if balance.Cmp(cfg.threshold) < 0 {
+			if !alerted {
+				alerted = true
+				sendBalanceAlert(cfg, balance)
+			}
+		} else if alerted {
+			alerted = false
+			log.Printf("Balance monitor: deployer balance recovered to %s ETH", formatEther(balance))
+		}
+
+		<-ticker.C
+	}
+}
+
+func sendBalanceAlert(cfg *balanceAlertConfig, balance *big.Int) {
+	message := fmt.Sprintf("Deployer %s balance %s ETH is below threshold %s ETH",
+		fromAddress.Hex(), formatEther(balance), formatEther(cfg.threshold))
+	log.Printf("CRITICAL: %s", message)
+
+	if cfg.webhookURL == "" {
+		return
+	}
+
+	body, _ := json.Marshal(balanceAlertPayload{
+		Account:   fromAddress.Hex(),
+		Balance:   balance.String(),
+		Threshold: cfg.threshold.String(),
+		Message:   message,
+	})
+	resp, err := httpClient.Post(cfg.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Balance monitor: failed to send webhook: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("Balance monitor: webhook returned status %d", resp.StatusCode)
+	}
+}
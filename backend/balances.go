@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+)
+
+const multicall3ABI = `[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type BalancesRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+type AddressBalance struct {
+	Address   string `json:"address"`
+	Balance   string `json:"balance,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type BalancesResponse struct {
+	Success  bool             `json:"success"`
+	Message  string           `json:"message,omitempty"`
+	Balances []AddressBalance `json:"balances"`
+}
+
+type BalanceResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Balance   string `json:"balance,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+	Decimals  int    `json:"decimals"`
+}
+
+type SupplyResponse struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message,omitempty"`
+	TotalSupply string `json:"totalSupply,omitempty"`
+	Formatted   string `json:"formatted,omitempty"`
+	Decimals    int    `json:"decimals"`
+}
+
+type AllowanceResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	Spender   string `json:"spender,omitempty"`
+	Allowance string `json:"allowance,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+	Decimals  int    `json:"decimals"`
+}
+
+type balanceQueryConfig struct {
+	maxAddresses     int
+	concurrency      int
+	multicallAddress *common.Address
+}
+
+var balanceQuery balanceQueryConfig
+
+func loadBalanceQueryConfig() error {
+	maxAddresses, err := envInt("BALANCES_MAX_ADDRESSES", 100)
+	if err != nil {
+		return err
+	}
+	concurrency, err := envInt("BALANCES_CONCURRENCY", 8)
+	if err != nil {
+		return err
+	}
+	if maxAddresses <= 0 || concurrency <= 0 {
+		return fmt.Errorf("BALANCES_MAX_ADDRESSES and BALANCES_CONCURRENCY must be positive")
+	}
+
+	balanceQuery = balanceQueryConfig{maxAddresses: maxAddresses, concurrency: concurrency}
+	if value := os.Getenv("MULTICALL_ADDRESS"); value != "" {
+		if !common.IsHexAddress(value) {
+			return fmt.Errorf("invalid MULTICALL_ADDRESS")
+		}
+		address := common.HexToAddress(value)
+		balanceQuery.multicallAddress = &address
+	}
+	return nil
+}
+
+func balanceHandler(w http.ResponseWriter, r *http.Request) {
+	input := mux.Vars(r)["address"]
+	if !common.IsHexAddress(input) {
+		respondWithJSON(w, http.StatusBadRequest, BalanceResponse{Message: "Invalid Ethereum address", Decimals: tokenDecimals})
+		return
+	}
+	address := common.HexToAddress(input)
+
+	balance, err := contract.BalanceOf(&bind.CallOpts{Context: r.Context()}, address)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, BalanceResponse{
+			Message:  fmt.Sprintf("Failed to read balance: %v", err),
+			Address:  address.Hex(),
+			Decimals: tokenDecimals,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, BalanceResponse{
+		Success:   true,
+		Address:   address.Hex(),
+		Balance:   balance.String(),
+		Formatted: formatUnits(balance, tokenDecimals),
+		Decimals:  tokenDecimals,
+	})
+}
+
+func supplyHandler(w http.ResponseWriter, r *http.Request) {
+	supply, err := contract.TotalSupply(&bind.CallOpts{Context: r.Context()})
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, SupplyResponse{
+			Message:  fmt.Sprintf("Failed to read total supply: %v", err),
+			Decimals: tokenDecimals,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SupplyResponse{
+		Success:     true,
+		TotalSupply: supply.String(),
+		Formatted:   formatUnits(supply, tokenDecimals),
+		Decimals:    tokenDecimals,
+	})
+}
+
+func allowanceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !common.IsHexAddress(vars["owner"]) || !common.IsHexAddress(vars["spender"]) {
+		respondWithJSON(w, http.StatusBadRequest, AllowanceResponse{Message: "Invalid Ethereum address", Decimals: tokenDecimals})
+		return
+	}
+	owner := common.HexToAddress(vars["owner"])
+	spender := common.HexToAddress(vars["spender"])
+
+	allowance, err := contract.Allowance(&bind.CallOpts{Context: r.Context()}, owner, spender)
+	if err != nil {
+		respondWithJSON(w, http.StatusInternalServerError, AllowanceResponse{
+			Message:  fmt.Sprintf("Failed to read allowance: %v", err),
+			Owner:    owner.Hex(),
+			Spender:  spender.Hex(),
+			Decimals: tokenDecimals,
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, AllowanceResponse{
+		Success:   true,
+		Owner:     owner.Hex(),
+		Spender:   spender.Hex(),
+		Allowance: allowance.String(),
+		Formatted: formatUnits(allowance, tokenDecimals),
+		Decimals:  tokenDecimals,
+	})
+}
+
+func balancesHandler(w http.ResponseWriter, r *http.Request) {
+	var req BalancesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if len(req.Addresses) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one address is required")
+		return
+	}
+	if len(req.Addresses) > balanceQuery.maxAddresses {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d addresses may be queried at once", balanceQuery.maxAddresses))
+		return
+	}
+
+	results := make([]AddressBalance, len(req.Addresses))
+	var valid []int
+	for i, address := range req.Addresses {
+		results[i].Address = address
+		if !common.IsHexAddress(address) {
+			results[i].Error = "Invalid Ethereum address"
+			continue
+		}
+		results[i].Address = common.HexToAddress(address).Hex()
+		valid = append(valid, i)
+	}
+
+	var balances []*big.Int
+	var errs []error
+	var err error
+	if balanceQuery.multicallAddress != nil {
+		balances, errs, err = multicallBalances(results, valid)
+	} else {
+		balances, errs = concurrentBalances(results, valid)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to query balances: %v", err))
+		return
+	}
+
+	for n, i := range valid {
+		if errs[n] != nil {
+			results[i].Error = errs[n].Error()
+			continue
+		}
+		results[i].Balance = balances[n].String()
+		results[i].Formatted = formatUnits(balances[n], tokenDecimals)
+	}
+
+	respondWithJSON(w, http.StatusOK, BalancesResponse{Success: true, Balances: results})
+}
+
+func concurrentBalances(results []AddressBalance, valid []int) ([]*big.Int, []error) {
+	balances := make([]*big.Int, len(valid))
+	errs := make([]error, len(valid))
+	sem := make(chan struct{}, balanceQuery.concurrency)
+
+	var wg sync.WaitGroup
+	for n, i := range valid {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n int, address common.Address) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			balances[n], errs[n] = contract.BalanceOf(&bind.CallOpts{Context: context.Background()}, address)
+		}(n, common.HexToAddress(results[i].Address))
+	}
+	wg.Wait()
+
+	return balances, errs
+}
+
+func multicallBalances(results []AddressBalance, valid []int) ([]*big.Int, []error, error) {
+	tokenABI, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return nil, nil, err
+	}
+	multicall, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	calls := make([]multicall3Call, len(valid))
+	for n, i := range valid {
+		data, err := tokenABI.Pack("balanceOf", common.HexToAddress(results[i].Address))
+		if err != nil {
+			return nil, nil, err
+		}
+		calls[n] = multicall3Call{Target: contractAddr, AllowFailure: true, CallData: data}
+	}
+
+	input, err := multicall.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := client.CallContract(context.Background(), ethereum.CallMsg{To: balanceQuery.multicallAddress, Data: input}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var decoded []multicall3Result
+	if err := multicall.UnpackIntoInterface(&decoded, "aggregate3", output); err != nil {
+		return nil, nil, err
+	}
+	if len(decoded) != len(valid) {
+		return nil, nil, fmt.Errorf("multicall returned %d results for %d calls", len(decoded), len(valid))
+	}
+
+	balances := make([]*big.Int, len(valid))
+	errs := make([]error, len(valid))
+	for n, result := range decoded {
+		if !result.Success || len(result.ReturnData) < 32 {
+			errs[n] = fmt.Errorf("balanceOf call failed")
+			continue
+		}
+		balances[n] = new(big.Int).SetBytes(result.ReturnData[:32])
+	}
+	return balances, errs, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
+)
+
+func TestBalanceHandler(t *testing.T) {
+	balance, _ := new(big.Int).SetString("12500000000000000000", 10)
+
+	tests := []struct {
+		name          string
+		address       string
+		callErr       error
+		wantStatus    int
+		wantBalance   string
+		wantFormatted string
+		wantMessage   string
+	}{
+		{
+			name:          "balance",
+			address:       testRecipient.Hex(),
+			wantStatus:    http.StatusOK,
+			wantBalance:   "12500000000000000000",
+			wantFormatted: "12.5",
+		},
+		{
+			name:        "malformed address",
+			address:     "0x1234",
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid Ethereum address",
+		},
+		{
+			name:        "contract call fails",
+			address:     testRecipient.Hex(),
+			callErr:     errors.New("header not found"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to read balance: header not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calledFor string
+			net, _ := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+				"eth_call": func(params []json.RawMessage) (any, error) {
+					var call struct{ Input hexutil.Bytes }
+					json.Unmarshal(params[0], &call)
+					if len(call.Input) == 36 {
+						calledFor = hexutil.Encode(call.Input[16:36])
+					}
+					if tt.callErr != nil {
+						return nil, tt.callErr
+					}
+					return encodeUint256(balance), nil
+				},
+			})
+			setGlobal(t, &contract, net.contract)
+			setGlobal(t, &tokenDecimals, 18)
+
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/balance/"+tt.address, nil), map[string]string{"address": tt.address})
+			w := httptest.NewRecorder()
+			balanceHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp BalanceResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Balance != tt.wantBalance || resp.Formatted != tt.wantFormatted || resp.Message != tt.wantMessage {
+				t.Errorf("response = %+v", resp)
+			}
+			if resp.Decimals != 18 {
+				t.Errorf("decimals = %d, want 18", resp.Decimals)
+			}
+			if tt.wantStatus != http.StatusBadRequest && !strings.EqualFold(calledFor, testRecipient.Hex()) {
+				t.Errorf("balanceOf called for %q, want %s", calledFor, testRecipient.Hex())
+			}
+		})
+	}
+}
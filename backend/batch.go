@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type BatchMintResult struct {
+	Index   int    `json:"index"`
+	Company string `json:"company"`
+	MintResponse
+}
+
+type BatchMintResponse struct {
+	Success       bool              `json:"success"`
+	Message       string            `json:"message"`
+	Succeeded     int               `json:"succeeded"`
+	Failed        int               `json:"failed"`
+	Results       []BatchMintResult `json:"results"`
+	CorrelationID string            `json:"correlationId,omitempty"`
+}
+
+var maxBatchEntries int
+
+func loadBatchMintConfig() error {
+	var err error
+	maxBatchEntries, err = envInt("BATCH_MAX_ENTRIES", 100)
+	if err != nil {
+		return err
+	}
+	if maxBatchEntries <= 0 {
+		return fmt.Errorf("BATCH_MAX_ENTRIES must be positive")
+	}
+	return nil
+}
+
+// batchMintHandler validates every entry before anything is sent, then
+// broadcasts the mints one after another so they take consecutive nonces.
+// Failures after validation (cooldowns, reverts) are reported per entry and
+// do not stop the rest of the batch.
+func batchMintHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []MintRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if len(reqs) == 0 {
+		respondWithError(w, http.StatusBadRequest, "At least one mint is required")
+		return
+	}
+	if len(reqs) > maxBatchEntries {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d mints may be submitted at once", maxBatchEntries))
+		return
+	}
+
+	verr := &validationError{}
+	amounts := make([]*big.Int, len(reqs))
+	nets := make([]*network, len(reqs))
+	for i, req := range reqs {
+		if req.DryRun {
+			verr.add(fmt.Sprintf("entry %d: dryRun is not supported in batches", i))
+			continue
+		}
+		net, err := networkFor(req.Network)
+		if err != nil {
+			verr.add(fmt.Sprintf("entry %d: %v", i, err))
+			continue
+		}
+		amount, err := validateMintRequest(req)
+		if err != nil {
+			verr.add(fmt.Sprintf("entry %d: %v", i, err))
+			continue
+		}
+		amounts[i] = amount
+		nets[i] = net
+	}
+	if len(verr.problems) > 0 {
+		respondWithValidationError(w, verr)
+		return
+	}
+
+	wait := syncConfirm
+	if value := r.URL.Query().Get("wait"); value != "" {
+		var err error
+		wait, err = strconv.ParseBool(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "wait must be true or false")
+			return
+		}
+	}
+
+	total := new(big.Int)
+	for _, amount := range amounts {
+		total.Add(total, amount)
+	}
+	if message := reserveMint(r.Context(), total); message != "" {
+		respondWithError(w, http.StatusTooManyRequests, message)
+		return
+	}
+
+	results := make([]BatchMintResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = BatchMintResult{Index: i, Company: common.HexToAddress(req.Company).Hex()}
+	}
+
+	status := http.StatusAccepted
+	if wait {
+		status = http.StatusOK
+		mintBatchSync(results, nets, amounts)
+	} else {
+		for i := range results {
+			_, results[i].MintResponse = mintAsync(nets[i], common.HexToAddress(results[i].Company), amounts[i])
+		}
+	}
+	for i := range results {
+		results[i].Network = nets[i].label()
+	}
+
+	resp := BatchMintResponse{Results: results, CorrelationID: correlationID(r.Context())}
+	for i, result := range results {
+		if !result.mayBeOnChain() {
+			releaseMint(r.Context(), amounts[i])
+		}
+		recordMint("batch", nets[i], common.HexToAddress(result.Company), reqs[i].Sales, amounts[i], result.MintResponse)
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+			log.Printf("[%s] Batch mint %d to %s failed: %s", resp.CorrelationID, result.Index, result.Company, result.Message)
+		}
+	}
+	resp.Success = resp.Failed == 0
+	resp.Message = fmt.Sprintf("%d of %d mints succeeded", resp.Succeeded, len(results))
+	if !wait {
+		resp.Message = fmt.Sprintf("%d of %d mints broadcast", resp.Succeeded, len(results))
+	}
+
+	respondWithJSON(w, status, resp)
+}
+
+// mintBatchSync broadcasts every entry in order and then waits for all the
+// receipts together, so one slow block does not serialise the whole batch.
+func mintBatchSync(results []BatchMintResult, nets []*network, amounts []*big.Int) {
+	txs := make([]*types.Transaction, len(results))
+
+	stats.inFlightMints.Add(int64(len(results)))
+	for i := range results {
+		txs[i], _, results[i].MintResponse = broadcastMint(nets[i], common.HexToAddress(results[i].Company), amounts[i])
+		if txs[i] == nil {
+			stats.inFlightMints.Add(-1)
+			stats.recordOutcome(false)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, tx *types.Transaction) {
+			defer wg.Done()
+			defer stats.inFlightMints.Add(-1)
+
+			_, results[i].MintResponse = confirmMint(nets[i], tx, common.HexToAddress(results[i].Company), amounts[i])
+			stats.recordOutcome(results[i].Success)
+		}(i, tx)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestBatchMintHandler(t *testing.T) {
+	reverting := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// The pending-state simulation reverts for one recipient only.
+	handlers := mintNodeHandlers()
+	handlers["eth_call"] = func(params []json.RawMessage) (any, error) {
+		var call struct{ Input hexutil.Bytes }
+		json.Unmarshal(params[0], &call)
+		if len(call.Input) >= 36 && common.BytesToAddress(call.Input[4:36]) == reverting {
+			return nil, &rpcErrorPayload{Code: 3, Message: "execution reverted", Data: revertErrorString}
+		}
+		return "0x", nil
+	}
+
+	tests := []struct {
+		name          string
+		entries       []common.Address
+		sales         string
+		wantStatus    int
+		wantSucceeded int
+		wantFailed    []int
+		wantNonces    []uint64
+	}{
+		{
+			name:          "all valid",
+			entries:       []common.Address{testRecipient, common.HexToAddress("0x3333333333333333333333333333333333333333")},
+			sales:         "2",
+			wantStatus:    http.StatusOK,
+			wantSucceeded: 2,
+			wantNonces:    []uint64{7, 8},
+		},
+		{
+			name:          "reverting entry in the middle",
+			entries:       []common.Address{testRecipient, reverting, common.HexToAddress("0x3333333333333333333333333333333333333333")},
+			sales:         "2",
+			wantStatus:    http.StatusOK,
+			wantSucceeded: 2,
+			wantFailed:    []int{1},
+			wantNonces:    []uint64{7, 8},
+		},
+		{
+			name:       "invalid entry rejects the whole batch",
+			entries:    []common.Address{testRecipient, testRecipient},
+			sales:      "-1",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BATCH_MAX_ENTRIES", "")
+			keepGlobal(t, &maxBatchEntries)
+			if err := loadBatchMintConfig(); err != nil {
+				t.Fatal(err)
+			}
+			_, broadcaster := newMintTestNetwork(t, handlers, 7)
+
+			entries := make([]string, len(tt.entries))
+			for i, to := range tt.entries {
+				entries[i] = fmt.Sprintf(`{"company":%q,"sales":%q}`, to.Hex(), tt.sales)
+			}
+			body := "[" + strings.Join(entries, ",") + "]"
+			req := httptest.NewRequest(http.MethodPost, "/mint/batch?wait=true", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			batchMintHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			sent := broadcaster.sent()
+			if len(sent) != len(tt.wantNonces) {
+				t.Fatalf("broadcast %d transactions, want %d", len(sent), len(tt.wantNonces))
+			}
+			for i, tx := range sent {
+				if tx.Nonce() != tt.wantNonces[i] {
+					t.Errorf("transaction %d nonce = %d, want %d", i, tx.Nonce(), tt.wantNonces[i])
+				}
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp BatchMintResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Succeeded != tt.wantSucceeded || resp.Failed != len(tt.wantFailed) {
+				t.Errorf("succeeded/failed = %d/%d, want %d/%d", resp.Succeeded, resp.Failed, tt.wantSucceeded, len(tt.wantFailed))
+			}
+			if resp.Success != (len(tt.wantFailed) == 0) {
+				t.Errorf("success = %v", resp.Success)
+			}
+			failed := make(map[int]bool)
+			for _, i := range tt.wantFailed {
+				failed[i] = true
+			}
+			for i, result := range resp.Results {
+				if result.Index != i || result.Company != tt.entries[i].Hex() {
+					t.Errorf("result %d = index %d company %s", i, result.Index, result.Company)
+				}
+				if failed[i] {
+					if result.Success || result.TxHash != "" || !strings.Contains(result.Message, "Not an authorized minter") {
+						t.Errorf("result %d = %+v, want a revert without a transaction", i, result.MintResponse)
+					}
+				} else if !result.Success || result.TxHash == "" {
+					t.Errorf("result %d = %+v, want a mined mint", i, result.MintResponse)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Broadcaster submits signed transactions and waits for them to be mined.
+// Handlers sign transactions locally and hand them to the configured
+// broadcaster, so alternative send strategies can be added without touching
+// the mint flow.
+type Broadcaster interface {
+	Broadcast(ctx context.Context, tx *types.Transaction) error
+	WaitMined(tx *types.Transaction) (*types.Receipt, error)
+}
+
+// publicMempoolBroadcaster sends transactions to the connected node's public
+// mempool and polls it for receipts, re-sending with higher fees when
+// GAS_BUMP_AFTER is set and the transaction is slow to be mined.
+type publicMempoolBroadcaster struct {
+	net *network
+}
+
+func (b publicMempoolBroadcaster) Broadcast(ctx context.Context, tx *types.Transaction) error {
+	return b.net.client.SendTransaction(ctx, tx)
+}
+
+func (b publicMempoolBroadcaster) WaitMined(tx *types.Transaction) (*types.Receipt, error) {
+	if gasBump.after > 0 {
+		return waitMinedWithBumps(b.net, tx)
+	}
+	return waitForTransaction(b.net, tx.Hash())
+}
+
+// broadcastRejections are the node errors that mean a transaction was
+// refused and is not in any mempool. "already known" is deliberately absent:
+// the node already has the transaction.
+var broadcastRejections = []string{
+	"nonce too low",
+	"nonce too high",
+	"invalid nonce",
+	"underpriced",
+	"insufficient funds",
+	"intrinsic gas too low",
+	"exceeds block gas limit",
+	"exceeds the configured cap",
+	"invalid sender",
+	"transaction type not supported",
+	"only replay-protected",
+}
+
+// isBroadcastRejection reports whether a Broadcast error proves the
+// transaction was not accepted. Other errors, such as timeouts or dropped
+// connections, leave open whether it reached the node.
+func isBroadcastRejection(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, rejection := range broadcastRejections {
+		if strings.Contains(msg, rejection) {
+			return true
+		}
+	}
+	return false
+}
+
+var broadcasters = map[string]func(net *network) (Broadcaster, error){
+	"public": func(net *network) (Broadcaster, error) { return publicMempoolBroadcaster{net: net}, nil },
+}
+
+// loadBroadcaster builds the BROADCASTER strategy for a network.
+func loadBroadcaster(net *network) (Broadcaster, error) {
+	name := os.Getenv("BROADCASTER")
+	if name == "" {
+		name = "public"
+	}
+
+	factory, ok := broadcasters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown BROADCASTER %q", name)
+	}
+	return factory(net)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// checkAllowedChain refuses to operate when ALLOWED_CHAIN_IDS is set and the
+// connected chain is not one of the comma-separated IDs it lists.
+func checkAllowedChain(chainID *big.Int, allowed string) error {
+	if strings.TrimSpace(allowed) == "" {
+		return nil
+	}
+
+	ids, err := parseChainIDs("ALLOWED_CHAIN_IDS", allowed)
+	if err != nil {
+		return err
+	}
+	if containsChainID(ids, chainID) {
+		return nil
+	}
+	return fmt.Errorf("connected chain ID %s is not in ALLOWED_CHAIN_IDS (%s)", chainID, allowed)
+}
+
+// parseChainIDs parses a comma-separated list of decimal chain IDs read from
+// the environment variable key.
+func parseChainIDs(key, value string) ([]*big.Int, error) {
+	var ids []*big.Int
+	for _, part := range strings.Split(value, ",") {
+		id, ok := new(big.Int).SetString(strings.TrimSpace(part), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid chain ID %q in %s", part, key)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func containsChainID(ids []*big.Int, chainID *big.Int) bool {
+	for _, id := range ids {
+		if id.Cmp(chainID) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCheckAllowedChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID int64
+		allowed string
+		wantErr bool
+	}{
+		{name: "unset allows any chain", chainID: 1, allowed: ""},
+		{name: "blank allows any chain", chainID: 1, allowed: "  "},
+		{name: "listed", chainID: 11155111, allowed: "11155111"},
+		{name: "listed among several", chainID: 137, allowed: "1, 137,8453"},
+		{name: "not listed", chainID: 1, allowed: "11155111,17000", wantErr: true},
+		{name: "invalid entry", chainID: 1, allowed: "1,mainnet", wantErr: true},
+		{name: "hex is not accepted", chainID: 1, allowed: "0x1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAllowedChain(big.NewInt(tt.chainID), tt.allowed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkAllowedChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+)
+
+type ChecksumResponse struct {
+	Success          bool   `json:"success"`
+	Message          string `json:"message,omitempty"`
+	Address          string `json:"address,omitempty"`
+	InputChecksummed bool   `json:"inputChecksummed"`
+}
+
+func checksumHandler(w http.ResponseWriter, r *http.Request) {
+	input := mux.Vars(r)["address"]
+	if !common.IsHexAddress(input) {
+		respondWithJSON(w, http.StatusBadRequest, ChecksumResponse{Message: "Invalid Ethereum address"})
+		return
+	}
+
+	checksummed := common.HexToAddress(input).Hex()
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X")
+	mixedCase := hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart)
+	if mixedCase && "0x"+hexPart != checksummed {
+		respondWithJSON(w, http.StatusBadRequest, ChecksumResponse{Message: "Address has an invalid EIP-55 checksum"})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChecksumResponse{
+		Success:          true,
+		Address:          checksummed,
+		InputChecksummed: "0x"+hexPart == checksummed,
+	})
+}
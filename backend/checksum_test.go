@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestChecksumHandler(t *testing.T) {
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+	tests := []struct {
+		name          string
+		input         string
+		wantStatus    int
+		wantAddress   string
+		wantChecksum  bool
+		wantErrorText string
+	}{
+		{name: "lowercase", input: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wantStatus: http.StatusOK, wantAddress: checksummed},
+		{name: "uppercase", input: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", wantStatus: http.StatusOK, wantAddress: checksummed},
+		{name: "already checksummed", input: checksummed, wantStatus: http.StatusOK, wantAddress: checksummed, wantChecksum: true},
+		{name: "bad checksum", input: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantStatus: http.StatusBadRequest, wantErrorText: "Address has an invalid EIP-55 checksum"},
+		{name: "too short", input: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", wantStatus: http.StatusBadRequest, wantErrorText: "Invalid Ethereum address"},
+		{name: "not hex", input: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaez", wantStatus: http.StatusBadRequest, wantErrorText: "Invalid Ethereum address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/checksum/"+tt.input, nil), map[string]string{"address": tt.input})
+			w := httptest.NewRecorder()
+			checksumHandler(w, req)
+
+			var resp ChecksumResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response body %q: %v", w.Body.String(), err)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if resp.Address != tt.wantAddress || resp.InputChecksummed != tt.wantChecksum || resp.Message != tt.wantErrorText {
+				t.Errorf("response = %+v", resp)
+			}
+		})
+	}
+}
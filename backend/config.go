@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+func envBool(key string, def bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return parsed, nil
+}
+
+func envInt(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return parsed, nil
+}
+
+func envFloat(key string, def float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return parsed, nil
+}
+
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return parsed, nil
+}
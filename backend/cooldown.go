@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var cooldownSignaturePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\(address\)$`)
+
+// cooldownSelector is the 4-byte selector of the token's optional on-chain
+// cooldown getter, configured via MINT_COOLDOWN_METHOD as a signature such as
+// "mintCooldownRemaining(address)". The getter must return the remaining
+// cooldown in seconds as a uint256. Nil when unconfigured.
+var cooldownSelector []byte
+
+func loadCooldownConfig() error {
+	signature := strings.ReplaceAll(os.Getenv("MINT_COOLDOWN_METHOD"), " ", "")
+	if signature == "" {
+		return nil
+	}
+	if !cooldownSignaturePattern.MatchString(signature) {
+		return fmt.Errorf("MINT_COOLDOWN_METHOD must look like name(address), got %q", signature)
+	}
+	cooldownSelector = crypto.Keccak256([]byte(signature))[:4]
+	return nil
+}
+
+// remainingCooldown returns the seconds left before the target may be minted
+// to again, or zero when no cooldown getter is configured.
+func remainingCooldown(net *network, target common.Address) (*big.Int, error) {
+	if cooldownSelector == nil {
+		return new(big.Int), nil
+	}
+
+	data := append(append([]byte{}, cooldownSelector...), common.LeftPadBytes(target.Bytes(), 32)...)
+	result, err := net.client.CallContract(context.Background(), ethereum.CallMsg{To: &net.contractAddr, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mint cooldown: %v", err)
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("unexpected mint cooldown result length %d", len(result))
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const correlationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+var validCorrelationID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// the event stream needs to flush.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// correlationMiddleware accepts an incoming X-Correlation-ID (or generates
+// one), echoes it in the response header and request log, and makes it
+// available to handlers through the request context.
+func correlationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(correlationIDHeader)
+		if !validCorrelationID.MatchString(id) {
+			id = newCorrelationID()
+		}
+
+		w.Header().Set(correlationIDHeader, id)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), correlationIDKey{}, id)))
+
+		log.Printf("[%s] %s %s -> %d (%s)", id, r.Method, r.URL.Path, recorder.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+func newCorrelationID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func correlationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const defaultTokenDecimals = 18
+
+var tokenDecimals = defaultTokenDecimals
+
+// resolveTokenDecimals picks the token's decimals in order of precedence:
+// an explicit TOKEN_DECIMALS override, the contract's decimals() value,
+// TOKEN_DECIMALS_FALLBACK if decimals() cannot be read, and finally 18.
+func resolveTokenDecimals(readDecimals func() (uint8, error)) (int, error) {
+	if override := os.Getenv("TOKEN_DECIMALS"); override != "" {
+		return parseDecimals("TOKEN_DECIMALS", override)
+	}
+
+	decimals, err := readDecimals()
+	if err == nil {
+		return int(decimals), nil
+	}
+
+	if fallback := os.Getenv("TOKEN_DECIMALS_FALLBACK"); fallback != "" {
+		log.Printf("Warning: failed to read token decimals (%v), using TOKEN_DECIMALS_FALLBACK", err)
+		return parseDecimals("TOKEN_DECIMALS_FALLBACK", fallback)
+	}
+
+	log.Printf("Warning: failed to read token decimals (%v), defaulting to %d; set TOKEN_DECIMALS to override", err, defaultTokenDecimals)
+	return defaultTokenDecimals, nil
+}
+
+func parseDecimals(key, value string) (int, error) {
+	decimals, err := strconv.Atoi(value)
+	if err != nil || decimals < 0 || decimals > 77 {
+		return 0, fmt.Errorf("invalid %s: must be an integer between 0 and 77", key)
+	}
+	return decimals, nil
+}
+
+func readContractDecimals() (uint8, error) {
+	return contract.Decimals(&bind.CallOpts{Context: context.Background()})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveTokenDecimals(t *testing.T) {
+	readSix := func() (uint8, error) { return 6, nil }
+	readFails := func() (uint8, error) { return 0, errors.New("execution reverted") }
+
+	tests := []struct {
+		name     string
+		override string
+		fallback string
+		read     func() (uint8, error)
+		want     int
+		wantErr  bool
+	}{
+		{name: "contract value", read: readSix, want: 6},
+		{name: "override wins", override: "8", read: readSix, want: 8},
+		{name: "fallback when call fails", fallback: "2", read: readFails, want: 2},
+		{name: "fallback ignored when call succeeds", fallback: "2", read: readSix, want: 6},
+		{name: "default when call fails", read: readFails, want: defaultTokenDecimals},
+		{name: "invalid override", override: "abc", read: readSix, wantErr: true},
+		{name: "invalid fallback", fallback: "78", read: readFails, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TOKEN_DECIMALS", tt.override)
+			t.Setenv("TOKEN_DECIMALS_FALLBACK", tt.fallback)
+
+			got, err := resolveTokenDecimals(tt.read)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTokenDecimals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("resolveTokenDecimals() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+)
+
+type DecodedArgument struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type DecodeTxResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message,omitempty"`
+	TxHash    string            `json:"txHash"`
+	To        string            `json:"to,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Arguments []DecodedArgument `json:"arguments,omitempty"`
+}
+
+func decodeTxHandler(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["hash"]
+	if !isValidTxHash(hash) {
+		respondWithError(w, http.StatusBadRequest, "Invalid transaction hash: expected 0x-prefixed 32-byte hex")
+		return
+	}
+	txHash := common.HexToHash(hash)
+
+	tx, _, err := client.TransactionByHash(context.Background(), txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		respondWithError(w, http.StatusNotFound, "Transaction not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch transaction: %v", err))
+		return
+	}
+
+	resp := DecodeTxResponse{TxHash: txHash.Hex()}
+	if tx.To() != nil {
+		resp.To = tx.To().Hex()
+	}
+
+	if tx.To() == nil || *tx.To() != contractAddr {
+		resp.Message = fmt.Sprintf("Transaction does not target the token contract %s", contractAddr.Hex())
+		respondWithJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	data := tx.Data()
+	if len(data) < 4 {
+		resp.Message = "Transaction has no method call data"
+		respondWithJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to load contract ABI: %v", err))
+		return
+	}
+
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		resp.Message = fmt.Sprintf("Unknown method selector %x", data[:4])
+		respondWithJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to decode arguments: %v", err))
+		return
+	}
+
+	resp.Success = true
+	resp.Method = method.RawName
+	resp.Signature = method.Sig
+	for i, input := range method.Inputs {
+		value := values[i]
+		if stringer, ok := value.(fmt.Stringer); ok {
+			value = stringer.String()
+		}
+		resp.Arguments = append(resp.Arguments, DecodedArgument{Name: input.Name, Type: input.Type.String(), Value: value})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type EtherAmount struct {
+	Wei   string `json:"wei"`
+	Ether string `json:"ether"`
+}
+
+type FeeBreakdown struct {
+	BaseFee     *EtherAmount `json:"baseFee,omitempty"`
+	PriorityFee *EtherAmount `json:"priorityFee,omitempty"`
+	MaxFee      *EtherAmount `json:"maxFee,omitempty"`
+	LikelyFee   *EtherAmount `json:"likelyFee,omitempty"`
+	GasPrice    *EtherAmount `json:"gasPrice,omitempty"`
+}
+
+type EstimateResponse struct {
+	Success      bool         `json:"success"`
+	Message      string       `json:"message,omitempty"`
+	GasLimit     uint64       `json:"gasLimit"`
+	GasEstimated bool         `json:"gasEstimated"`
+	DynamicFees  bool         `json:"dynamicFees"`
+	Fees         FeeBreakdown `json:"fees"`
+	MaxCost      EtherAmount  `json:"maxCost"`
+	LikelyCost   EtherAmount  `json:"likelyCost"`
+}
+
+func etherAmount(wei *big.Int) EtherAmount {
+	return EtherAmount{Wei: wei.String(), Ether: formatEther(wei)}
+}
+
+func etherAmountPtr(wei *big.Int) *EtherAmount {
+	amount := etherAmount(wei)
+	return &amount
+}
+
+func estimateMintHandler(w http.ResponseWriter, r *http.Request) {
+	var req MintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	amount, err := validateMintRequest(req)
+	if err != nil {
+		respondWithValidationError(w, err)
+		return
+	}
+
+	net, err := networkFor(req.Network)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := EstimateResponse{Success: true, GasLimit: gasLimitFor("mint_secure")}
+	if gas, err := simulateMint(net, common.HexToAddress(req.Company), amount); err == nil {
+		resp.GasLimit = scaleGasLimit(gas)
+		resp.GasEstimated = true
+	} else if isRevertError(err) {
+		resp.Message = withRevertReason("Mint would revert; using default gas limit", decodeRevertReason(err))
+	}
+
+	ctx := context.Background()
+	gasLimit := new(big.Int).SetUint64(resp.GasLimit)
+
+	quote, err := suggestDynamicFees(ctx, net)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if quote != nil {
+		likelyFee := new(big.Int).Add(quote.baseFee, quote.tipCap)
+		if likelyFee.Cmp(quote.feeCap) > 0 {
+			likelyFee = quote.feeCap
+		}
+
+		resp.DynamicFees = true
+		resp.Fees = FeeBreakdown{
+			BaseFee:     etherAmountPtr(quote.baseFee),
+			PriorityFee: etherAmountPtr(quote.tipCap),
+			MaxFee:      etherAmountPtr(quote.feeCap),
+			LikelyFee:   etherAmountPtr(likelyFee),
+		}
+		resp.MaxCost = etherAmount(new(big.Int).Mul(quote.feeCap, gasLimit))
+		resp.LikelyCost = etherAmount(new(big.Int).Mul(likelyFee, gasLimit))
+	} else {
+		gasPrice, err := net.client.SuggestGasPrice(ctx)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get gas price: %v", err))
+			return
+		}
+		gasPrice = capGasPrice(gasPrice)
+		cost := etherAmount(new(big.Int).Mul(gasPrice, gasLimit))
+
+		resp.Fees = FeeBreakdown{GasPrice: etherAmountPtr(gasPrice)}
+		resp.MaxCost = cost
+		resp.LikelyCost = cost
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
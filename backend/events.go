@@ -0,0 +1,651 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	eventTransfer   = "Transfer"
+	eventMintSecure = "MintSecure"
+)
+
+// TokenEvent is one indexed Transfer or MintSecure log. Seq is assigned in
+// indexing order and doubles as the SSE event ID and the pagination cursor.
+// Plain ERC-20 mints show up as a Transfer from the zero address; mint_secure
+// emits that Transfer as well as its own MintSecure event.
+type TokenEvent struct {
+	Seq         uint64 `json:"seq"`
+	Network     string `json:"network,omitempty"`
+	Event       string `json:"event"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+	Formatted   string `json:"formatted"`
+	TxHash      string `json:"txHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	LogIndex    uint   `json:"logIndex"`
+}
+
+type EventFilter struct {
+	Network   string
+	Event     string
+	Address   *common.Address
+	FromBlock uint64
+	ToBlock   uint64
+	Before    uint64
+	Limit     int
+}
+
+type EventsResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Events  []TokenEvent `json:"events"`
+	Next    uint64       `json:"next,omitempty"`
+}
+
+type eventIndexerConfig struct {
+	interval   time.Duration
+	blockRange uint64
+	startBlock int
+	safeDepth  uint64
+	maxStreams int
+}
+
+var (
+	tokenEvents   *eventStore
+	eventIndexing eventIndexerConfig
+	activeStreams atomic.Int64
+)
+
+// loadEventIndexer starts indexing the token's events on every network when
+// EVENTS_FILE is set. Without EVENTS_START_BLOCK indexing starts at the
+// current head the first time, then resumes from the last indexed block.
+// Indexed events are never revisited, so only blocks EVENTS_SAFE_DEPTH
+// behind the head are indexed, deep enough that a reorg will not replace
+// them; unlike CONFIRMATIONS this defaults to 12, and 0 suits local chains.
+// EVENTS_MAX_STORED bounds how many events are kept, dropping the oldest.
+func loadEventIndexer() error {
+	path := os.Getenv("EVENTS_FILE")
+	if path == "" || !featureEnabled(featureEvents) {
+		return nil
+	}
+
+	interval, err := envDuration("EVENTS_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return err
+	}
+	blockRange, err := envInt("EVENTS_BLOCK_RANGE", 2000)
+	if err != nil {
+		return err
+	}
+	startBlock, err := envInt("EVENTS_START_BLOCK", -1)
+	if err != nil {
+		return err
+	}
+	safeDepth, err := envInt("EVENTS_SAFE_DEPTH", 12)
+	if err != nil {
+		return err
+	}
+	maxStreams, err := envInt("EVENTS_MAX_STREAMS", 100)
+	if err != nil {
+		return err
+	}
+	maxStored, err := envInt("EVENTS_MAX_STORED", 100000)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 || blockRange <= 0 || maxStreams <= 0 || maxStored <= 0 {
+		return fmt.Errorf("EVENTS_POLL_INTERVAL, EVENTS_BLOCK_RANGE, EVENTS_MAX_STREAMS and EVENTS_MAX_STORED must be positive")
+	}
+	if safeDepth < 0 {
+		return fmt.Errorf("EVENTS_SAFE_DEPTH must not be negative")
+	}
+	eventIndexing = eventIndexerConfig{
+		interval:   interval,
+		blockRange: uint64(blockRange),
+		startBlock: startBlock,
+		safeDepth:  uint64(safeDepth),
+		maxStreams: maxStreams,
+	}
+
+	store, err := openEventStore(path, maxStored)
+	if err != nil {
+		return err
+	}
+	tokenEvents = store
+
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return fmt.Errorf("failed to parse token ABI: %v", err)
+	}
+	for _, net := range networks {
+		indexer := &eventIndexer{
+			net:           net,
+			transferTopic: parsed.Events[eventTransfer].ID,
+			mintTopic:     parsed.Events[eventMintSecure].ID,
+		}
+		go indexer.run()
+	}
+	return nil
+}
+
+// eventStore appends events to a JSON-lines file and keeps them in memory,
+// like the mint history. The last indexed block of each network lives in a
+// small "<EVENTS_FILE>.cursor" file that is rewritten after every range.
+// Only the newest maxStored events are kept in memory; the file is compacted
+// to those on start-up.
+type eventStore struct {
+	mu          sync.RWMutex
+	file        *jsonlFile[TokenEvent]
+	cursorPath  string
+	maxStored   int
+	events      []TokenEvent // ordered by Seq
+	lastSeq     uint64
+	seen        map[string]bool
+	cursors     map[string]uint64
+	subscribers map[chan TokenEvent]struct{}
+}
+
+func openEventStore(path string, maxStored int) (*eventStore, error) {
+	store := &eventStore{
+		cursorPath:  path + ".cursor",
+		maxStored:   maxStored,
+		seen:        make(map[string]bool),
+		cursors:     make(map[string]uint64),
+		subscribers: make(map[chan TokenEvent]struct{}),
+	}
+	file, err := openJSONLFile(path, "events file", func(event TokenEvent) {
+		store.events = append(store.events, event)
+		store.seen[event.key()] = true
+		store.lastSeq = max(store.lastSeq, event.Seq)
+	})
+	if err != nil {
+		return nil, err
+	}
+	store.prune()
+	if err := file.compact(store.events); err != nil {
+		file.close()
+		return nil, err
+	}
+
+	data, err := os.ReadFile(store.cursorPath)
+	if err != nil && !os.IsNotExist(err) {
+		file.close()
+		return nil, fmt.Errorf("failed to read events cursor: %v", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.cursors); err != nil {
+			file.close()
+			return nil, fmt.Errorf("failed to parse events cursor: %v", err)
+		}
+	}
+	store.file = file
+	return store, nil
+}
+
+// key identifies a log independently of Seq, so a range that is indexed
+// again after a crash does not produce duplicates.
+func (e TokenEvent) key() string {
+	return e.Network + "/" + e.TxHash + "/" + strconv.FormatUint(uint64(e.LogIndex), 10)
+}
+
+// append stores the events not seen before and pushes them to every open
+// stream. A stream that cannot keep up is closed; its client reconnects with
+// Last-Event-ID and catches up from the store.
+func (s *eventStore) append(events []TokenEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		if s.seen[event.key()] {
+			continue
+		}
+		event.Seq = s.lastSeq + 1
+		if err := s.file.append(event); err != nil {
+			return err
+		}
+		s.events = append(s.events, event)
+		s.lastSeq = event.Seq
+		s.seen[event.key()] = true
+
+		for ch := range s.subscribers {
+			select {
+			case ch <- event:
+			default:
+				delete(s.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+	s.prune()
+	return nil
+}
+
+// prune drops the oldest events beyond maxStored. Their keys go too: the
+// cursors are past their blocks, so they are not indexed again.
+func (s *eventStore) prune() {
+	drop := len(s.events) - s.maxStored
+	if drop <= 0 {
+		return
+	}
+	for _, event := range s.events[:drop] {
+		delete(s.seen, event.key())
+	}
+	s.events = slices.Clone(s.events[drop:])
+}
+
+func (s *eventStore) cursor(network string) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	block, ok := s.cursors[network]
+	return block, ok
+}
+
+func (s *eventStore) setCursor(network string, block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cursors[network] = block
+	data, err := json.Marshal(s.cursors)
+	if err != nil {
+		return err
+	}
+	tmp := s.cursorPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write events cursor: %v", err)
+	}
+	if err := os.Rename(tmp, s.cursorPath); err != nil {
+		return fmt.Errorf("failed to write events cursor: %v", err)
+	}
+	return nil
+}
+
+// list returns matching events newest first, starting below filter.Before,
+// up to filter.Limit of them.
+func (s *eventStore) list(filter EventFilter) []TokenEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := make([]TokenEvent, 0)
+	for i := len(s.events) - 1; i >= 0 && len(matches) < filter.Limit; i-- {
+		event := s.events[i]
+		if filter.Before != 0 && event.Seq >= filter.Before {
+			continue
+		}
+		if filter.matches(event) {
+			matches = append(matches, event)
+		}
+	}
+	return matches
+}
+
+// subscribe registers a stream and returns the matching events stored after
+// the given Seq, oldest first. Both happen under one lock so no event falls
+// between the replay and the live feed.
+func (s *eventStore) subscribe(after uint64, filter EventFilter) (chan TokenEvent, []TokenEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, _ := slices.BinarySearchFunc(s.events, after+1, func(event TokenEvent, seq uint64) int {
+		return cmp.Compare(event.Seq, seq)
+	})
+	var backlog []TokenEvent
+	for _, event := range s.events[start:] {
+		if filter.matches(event) {
+			backlog = append(backlog, event)
+		}
+	}
+	ch := make(chan TokenEvent, 64)
+	s.subscribers[ch] = struct{}{}
+	return ch, backlog
+}
+
+func (s *eventStore) unsubscribe(ch chan TokenEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (f EventFilter) matches(event TokenEvent) bool {
+	if f.Network != "" && event.Network != f.Network {
+		return false
+	}
+	if f.Event != "" && event.Event != f.Event {
+		return false
+	}
+	if f.Address != nil && event.From != f.Address.Hex() && event.To != f.Address.Hex() {
+		return false
+	}
+	if f.FromBlock != 0 && event.BlockNumber < f.FromBlock {
+		return false
+	}
+	if f.ToBlock != 0 && event.BlockNumber > f.ToBlock {
+		return false
+	}
+	return true
+}
+
+// eventIndexer polls one network's token logs. On a WebSocket endpoint it
+// also subscribes to new heads so each block is picked up as soon as it
+// arrives; the poll interval remains the fallback, and a dropped
+// subscription is re-established on the next pass. Every pass resumes from
+// the stored cursor, so reconnects and restarts do not lose events.
+type eventIndexer struct {
+	net           *network
+	transferTopic common.Hash
+	mintTopic     common.Hash
+}
+
+func (ix *eventIndexer) run() {
+	ticker := time.NewTicker(eventIndexing.interval)
+	defer ticker.Stop()
+
+	heads := make(chan *types.Header, 1)
+	var sub ethereum.Subscription
+	canSubscribe := true
+
+	for {
+		if sub == nil && canSubscribe {
+			var err error
+			sub, err = ix.net.client.SubscribeNewHead(context.Background(), heads)
+			if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+				canSubscribe = false
+			} else if err != nil {
+				log.Printf("Event indexer (%s): failed to subscribe to new heads: %v", ix.net.name, err)
+			}
+		}
+
+		if err := ix.poll(); err != nil {
+			log.Printf("Event indexer (%s): %v", ix.net.name, err)
+		}
+
+		var subErr <-chan error
+		if sub != nil {
+			subErr = sub.Err()
+		}
+		select {
+		case <-ticker.C:
+		case <-heads:
+		case err := <-subErr:
+			log.Printf("Event indexer (%s): head subscription dropped: %v", ix.net.name, err)
+			sub.Unsubscribe()
+			sub = nil
+		}
+	}
+}
+
+// poll indexes every block up to EVENTS_SAFE_DEPTH below the head, in ranges
+// of EVENTS_BLOCK_RANGE, saving the cursor after each range.
+func (ix *eventIndexer) poll() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	head, err := ix.net.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %v", err)
+	}
+	if head < eventIndexing.safeDepth {
+		return nil
+	}
+	safe := head - eventIndexing.safeDepth
+
+	var from uint64
+	if last, ok := tokenEvents.cursor(ix.net.name); ok {
+		from = last + 1
+	} else if eventIndexing.startBlock >= 0 {
+		from = uint64(eventIndexing.startBlock)
+	} else {
+		return tokenEvents.setCursor(ix.net.name, safe)
+	}
+
+	for from <= safe {
+		to := min(from+eventIndexing.blockRange-1, safe)
+		logs, err := ix.net.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{ix.net.contractAddr},
+			Topics:    [][]common.Hash{{ix.transferTopic, ix.mintTopic}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch logs for blocks %d-%d: %v", from, to, err)
+		}
+
+		events := make([]TokenEvent, 0, len(logs))
+		for _, l := range logs {
+			if l.Removed {
+				continue
+			}
+			event, err := ix.parse(l)
+			if err != nil {
+				log.Printf("Event indexer (%s): skipping log %d of tx %s: %v", ix.net.name, l.Index, l.TxHash.Hex(), err)
+				continue
+			}
+			events = append(events, event)
+		}
+		if err := tokenEvents.append(events); err != nil {
+			return err
+		}
+		if err := tokenEvents.setCursor(ix.net.name, to); err != nil {
+			return err
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+func (ix *eventIndexer) parse(l types.Log) (TokenEvent, error) {
+	event := TokenEvent{
+		Network:     ix.net.label(),
+		TxHash:      l.TxHash.Hex(),
+		BlockNumber: l.BlockNumber,
+		LogIndex:    l.Index,
+	}
+
+	var amount *big.Int
+	switch l.Topics[0] {
+	case ix.transferTopic:
+		transfer, err := ix.net.contract.ParseTransfer(l)
+		if err != nil {
+			return event, err
+		}
+		event.Event = eventTransfer
+		event.From = transfer.From.Hex()
+		event.To = transfer.To.Hex()
+		amount = transfer.Value
+	case ix.mintTopic:
+		mint, err := ix.net.contract.ParseMintSecure(l)
+		if err != nil {
+			return event, err
+		}
+		event.Event = eventMintSecure
+		event.To = mint.To.Hex()
+		amount = mint.Amount
+	default:
+		return event, fmt.Errorf("unexpected topic %s", l.Topics[0].Hex())
+	}
+
+	event.Amount = amount.String()
+	event.Formatted = formatUnits(amount, tokenDecimals)
+	return event, nil
+}
+
+// parseEventFilter reads the query parameters shared by GET /events and
+// GET /events/stream.
+func parseEventFilter(r *http.Request) (EventFilter, error) {
+	query := r.URL.Query()
+	filter := EventFilter{Limit: 100}
+
+	if name := query.Get("network"); name != "" {
+		net, err := networkFor(name)
+		if err != nil {
+			return filter, err
+		}
+		filter.Network = net.label()
+	}
+	switch event := query.Get("event"); {
+	case event == "":
+	case strings.EqualFold(event, eventTransfer):
+		filter.Event = eventTransfer
+	case strings.EqualFold(event, eventMintSecure):
+		filter.Event = eventMintSecure
+	default:
+		return filter, fmt.Errorf("event must be %s or %s", eventTransfer, eventMintSecure)
+	}
+	if address := query.Get("address"); address != "" {
+		if !common.IsHexAddress(address) {
+			return filter, fmt.Errorf("invalid address")
+		}
+		parsed := common.HexToAddress(address)
+		filter.Address = &parsed
+	}
+
+	blocks := []struct {
+		name string
+		dest *uint64
+	}{{"fromBlock", &filter.FromBlock}, {"toBlock", &filter.ToBlock}, {"before", &filter.Before}}
+	for _, param := range blocks {
+		if value := query.Get(param.name); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be a non-negative integer", param.name)
+			}
+			*param.dest = parsed
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return filter, fmt.Errorf("limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// eventsHandler lists indexed events newest first. When more remain, Next is
+// the value to pass as ?before= for the following page.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// One extra event tells whether another page exists.
+	limit := filter.Limit
+	filter.Limit++
+	events := tokenEvents.list(filter)
+	resp := EventsResponse{Success: true, Events: events}
+	if len(events) > limit {
+		resp.Events = events[:limit]
+		resp.Next = resp.Events[limit-1].Seq
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// eventStreamHandler pushes events as Server-Sent Events, each with its Seq
+// as the event ID. A client that reconnects with Last-Event-ID (or
+// ?lastEventId=) first receives everything it missed.
+func eventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseEventFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+	var after uint64
+	if lastID != "" {
+		if after, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Last-Event-ID must be a non-negative integer")
+			return
+		}
+	}
+
+	if activeStreams.Add(1) > int64(eventIndexing.maxStreams) {
+		activeStreams.Add(-1)
+		w.Header().Set("Retry-After", "5")
+		respondWithError(w, http.StatusServiceUnavailable, "Too many open event streams, please retry later")
+		return
+	}
+	defer activeStreams.Add(-1)
+
+	ch, backlog := tokenEvents.subscribe(after, filter)
+	defer tokenEvents.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	for _, event := range backlog {
+		if writeServerSentEvent(w, event) != nil {
+			return
+		}
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !filter.matches(event) {
+				continue
+			}
+			if writeServerSentEvent(w, event) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event TokenEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Event, data)
+	return err
+}
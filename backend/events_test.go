@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEventStoreKeepsNewestEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	event := func(tx string) TokenEvent {
+		return TokenEvent{Network: "test", Event: eventTransfer, TxHash: tx}
+	}
+	seqs := func(events []TokenEvent) []uint64 {
+		var out []uint64
+		for _, e := range events {
+			out = append(out, e.Seq)
+		}
+		return out
+	}
+
+	store, err := openEventStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.append([]TokenEvent{event("0x1"), event("0x2"), event("0x3")}); err != nil {
+		t.Fatal(err)
+	}
+	if got := seqs(store.list(EventFilter{Limit: 10})); len(got) != 2 || got[0] != 3 || got[1] != 2 {
+		t.Errorf("stored seqs = %v, want [3 2]", got)
+	}
+	store.file.close()
+
+	// Reopening compacts the file to the kept events and Seq carries on.
+	store, err = openEventStore(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.file.close()
+	if err := store.append([]TokenEvent{event("0x4")}); err != nil {
+		t.Fatal(err)
+	}
+	if got := seqs(store.list(EventFilter{Limit: 10})); len(got) != 2 || got[0] != 4 || got[1] != 3 {
+		t.Errorf("seqs after reopening = %v, want [4 3]", got)
+	}
+
+	// A stream resuming from a pruned Seq replays whatever is still kept.
+	_, backlog := store.subscribe(1, EventFilter{})
+	if got := seqs(backlog); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("backlog after Seq 1 = %v, want [3 4]", got)
+	}
+	_, backlog = store.subscribe(3, EventFilter{})
+	if got := seqs(backlog); len(got) != 1 || got[0] != 4 {
+		t.Errorf("backlog after Seq 3 = %v, want [4]", got)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	featureBalances = "balances"
+	featureBatch    = "batch"
+	featureChecksum = "checksum"
+	featureDryRun   = "dry-run"
+	featureEstimate = "estimate"
+	featureEvents   = "events"
+	featureHistory  = "history"
+	featureStats    = "stats"
+	featureTxDecode = "tx-decode"
+)
+
+// knownFeatures lists the optional endpoints and behaviours that can be
+// switched off per deployment. All of them are enabled unless FEATURES is set.
+var knownFeatures = []string{featureBalances, featureBatch, featureChecksum, featureDryRun, featureEstimate, featureEvents, featureHistory, featureStats, featureTxDecode}
+
+var enabledFeatures map[string]bool
+
+// loadFeatureFlags reads FEATURES, a comma-separated list of optional
+// features to enable. When unset every known feature is enabled; "none"
+// disables them all.
+func loadFeatureFlags() error {
+	enabledFeatures = make(map[string]bool)
+
+	value := strings.TrimSpace(os.Getenv("FEATURES"))
+	if value == "" {
+		for _, name := range knownFeatures {
+			enabledFeatures[name] = true
+		}
+		return nil
+	}
+	if value == "none" {
+		return nil
+	}
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !isKnownFeature(name) {
+			return fmt.Errorf("unknown feature %q in FEATURES (known: %s)", name, strings.Join(knownFeatures, ", "))
+		}
+		enabledFeatures[name] = true
+	}
+	return nil
+}
+
+func isKnownFeature(name string) bool {
+	for _, known := range knownFeatures {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+func featureEnabled(name string) bool {
+	return enabledFeatures[name]
+}
+
+func enabledFeatureList() []string {
+	names := make([]string, 0, len(enabledFeatures))
+	for name := range enabledFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
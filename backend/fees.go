@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+)
+
+// feeHistoryBlocks is how many recent blocks the priority fee is sampled
+// from.
+const feeHistoryBlocks = 10
+
+type feeConfig struct {
+	baseFeeMultiplier  float64
+	maxFeePerGas       *big.Int
+	priorityFee        *big.Int
+	gasLimitMultiplier float64
+}
+
+var fees feeConfig
+
+func loadFeeConfig() error {
+	multiplier, err := envFloat("BASE_FEE_MULTIPLIER", 2)
+	if err != nil {
+		return err
+	}
+	if multiplier < 1 {
+		return fmt.Errorf("BASE_FEE_MULTIPLIER must be at least 1")
+	}
+
+	gasMultiplier, err := envFloat("GAS_LIMIT_MULTIPLIER", 1.2)
+	if err != nil {
+		return err
+	}
+	if gasMultiplier < 1 {
+		return fmt.Errorf("GAS_LIMIT_MULTIPLIER must be at least 1")
+	}
+
+	// MAX_FEE_PER_GAS_GWEI is the older name for MAX_FEE_GWEI.
+	ceilingKey := "MAX_FEE_GWEI"
+	ceiling := os.Getenv(ceilingKey)
+	if ceiling == "" {
+		ceilingKey = "MAX_FEE_PER_GAS_GWEI"
+		ceiling = os.Getenv(ceilingKey)
+	}
+	if ceiling == "" {
+		ceiling = "500"
+	}
+	maxFee, err := parseUnits(ceiling, 9)
+	if err != nil || maxFee.Sign() <= 0 {
+		return fmt.Errorf("invalid %s %q", ceilingKey, ceiling)
+	}
+
+	fees = feeConfig{baseFeeMultiplier: multiplier, maxFeePerGas: maxFee, gasLimitMultiplier: gasMultiplier}
+
+	if value := os.Getenv("PRIORITY_FEE_GWEI"); value != "" {
+		tip, err := parseUnits(value, 9)
+		if err != nil || tip.Sign() < 0 {
+			return fmt.Errorf("invalid PRIORITY_FEE_GWEI %q", value)
+		}
+		if tip.Cmp(maxFee) > 0 {
+			return fmt.Errorf("PRIORITY_FEE_GWEI must not exceed MAX_FEE_GWEI")
+		}
+		fees.priorityFee = tip
+	}
+	return nil
+}
+
+// scaleGasLimit pads an EstimateGas result by GAS_LIMIT_MULTIPLIER, since
+// state can change between estimation and inclusion.
+func scaleGasLimit(estimate uint64) uint64 {
+	return uint64(math.Ceil(float64(estimate) * fees.gasLimitMultiplier))
+}
+
+// capGasPrice clamps a legacy gas price to MAX_FEE_GWEI.
+func capGasPrice(gasPrice *big.Int) *big.Int {
+	if gasPrice.Cmp(fees.maxFeePerGas) > 0 {
+		return new(big.Int).Set(fees.maxFeePerGas)
+	}
+	return gasPrice
+}
+
+type dynamicFeeQuote struct {
+	baseFee *big.Int
+	tipCap  *big.Int
+	feeCap  *big.Int
+}
+
+// suggestDynamicFees prices an EIP-1559 transaction as
+// feeCap = baseFee * BASE_FEE_MULTIPLIER + tip, clamped to MAX_FEE_GWEI. The
+// base fee is the next block's, from eth_feeHistory, and the tip is
+// PRIORITY_FEE_GWEI or else the median of the 50th-percentile rewards over
+// the last few blocks. Nodes without eth_feeHistory fall back to the latest
+// header and eth_maxPriorityFeePerGas. It returns nil when the chain reports
+// no base fee (pre-London) or unprotected signing is enabled, in which case
+// callers should fall back to legacy gas pricing.
+func suggestDynamicFees(ctx context.Context, net *network) (*dynamicFeeQuote, error) {
+	if disableEIP155 {
+		return nil, nil
+	}
+
+	baseFee, tip, err := feeHistoryQuote(ctx, net)
+	if err != nil {
+		header, err := net.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read latest block: %v", err)
+		}
+		baseFee, tip = header.BaseFee, nil
+	}
+	if baseFee == nil || baseFee.Sign() == 0 {
+		return nil, nil
+	}
+
+	if fees.priorityFee != nil {
+		tip = new(big.Int).Set(fees.priorityFee)
+	}
+	if tip == nil {
+		tip, err = net.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get priority fee: %v", err)
+		}
+	}
+
+	scaled, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(fees.baseFeeMultiplier)).Int(nil)
+	feeCap := scaled.Add(scaled, tip)
+	if feeCap.Cmp(fees.maxFeePerGas) > 0 {
+		feeCap = new(big.Int).Set(fees.maxFeePerGas)
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+
+	return &dynamicFeeQuote{baseFee: baseFee, tipCap: tip, feeCap: feeCap}, nil
+}
+
+// feeHistoryQuote returns the pending block's base fee and the median recent
+// priority fee, or a nil tip when no block in the window paid one.
+func feeHistoryQuote(ctx context.Context, net *network) (*big.Int, *big.Int, error) {
+	history, err := net.client.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{50})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, nil, fmt.Errorf("fee history has no base fees")
+	}
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	var rewards []*big.Int
+	for _, block := range history.Reward {
+		if len(block) > 0 && block[0] != nil && block[0].Sign() > 0 {
+			rewards = append(rewards, block[0])
+		}
+	}
+	if len(rewards) == 0 {
+		return baseFee, nil, nil
+	}
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+	return baseFee, new(big.Int).Set(rewards[len(rewards)/2]), nil
+}
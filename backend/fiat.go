@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fiatPriceFeed converts native gas costs to fiat using a coingecko-style
+// endpoint, e.g. /simple/price?ids=ethereum&vs_currencies=usd, which returns
+// {"ethereum": {"usd": 3150.12}}. Rates are cached for the configured TTL.
+type fiatPriceFeed struct {
+	url      string
+	currency string
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	rate      float64
+	fetchedAt time.Time
+}
+
+var priceFeed *fiatPriceFeed
+
+func loadFiatPriceFeed() (*fiatPriceFeed, error) {
+	url := os.Getenv("GAS_PRICE_FEED_URL")
+	if url == "" {
+		return nil, nil
+	}
+
+	ttl, err := envDuration("GAS_PRICE_FEED_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToLower(os.Getenv("FIAT_CURRENCY"))
+	if currency == "" {
+		currency = "usd"
+	}
+
+	return &fiatPriceFeed{url: url, currency: currency, ttl: ttl}, nil
+}
+
+func (f *fiatPriceFeed) currentRate() (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rate > 0 && time.Since(f.fetchedAt) < f.ttl {
+		return f.rate, nil
+	}
+
+	rate, err := f.fetch()
+	if err != nil {
+		return 0, err
+	}
+	f.rate = rate
+	f.fetchedAt = time.Now()
+	return rate, nil
+}
+
+func (f *fiatPriceFeed) fetch() (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("price feed returned status %d", resp.StatusCode)
+	}
+
+	var body any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("invalid price feed response: %v", err)
+	}
+
+	rate, ok := findRate(body, f.currency)
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("price feed response has no %q rate", f.currency)
+	}
+	return rate, nil
+}
+
+func findRate(value any, currency string) (float64, bool) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	if rate, ok := fields[currency].(float64); ok {
+		return rate, true
+	}
+	for _, nested := range fields {
+		if rate, ok := findRate(nested, currency); ok {
+			return rate, true
+		}
+	}
+	return 0, false
+}
+
+// convert returns the fiat value of a wei amount, or false if no rate is
+// currently available.
+func (f *fiatPriceFeed) convert(wei *big.Int) (float64, bool) {
+	rate, err := f.currentRate()
+	if err != nil {
+		log.Printf("Price feed unavailable, omitting fiat gas cost: %v", err)
+		return 0, false
+	}
+
+	ether, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return ether * rate, true
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type gasBumpConfig struct {
+	after              time.Duration
+	percent            int64
+	maxBumps           int
+	underpricedRetries int
+}
+
+var gasBump gasBumpConfig
+
+// loadGasBumpConfig reads the stuck-transaction settings. Bumping is off
+// unless GAS_BUMP_AFTER is set; nodes only accept a replacement that raises
+// the fees by at least 10%, so GAS_BUMP_PERCENT cannot go lower. Some nodes
+// demand more, and GAS_BUMP_UNDERPRICED_RETRIES bounds how many times a
+// replacement they reject as underpriced is re-signed with a bigger bump.
+func loadGasBumpConfig() error {
+	after, err := envDuration("GAS_BUMP_AFTER", 0)
+	if err != nil {
+		return err
+	}
+	percent, err := envInt("GAS_BUMP_PERCENT", 15)
+	if err != nil {
+		return err
+	}
+	maxBumps, err := envInt("GAS_BUMP_MAX", 3)
+	if err != nil {
+		return err
+	}
+	underpricedRetries, err := envInt("GAS_BUMP_UNDERPRICED_RETRIES", 3)
+	if err != nil {
+		return err
+	}
+	if after < 0 || maxBumps < 0 || underpricedRetries < 0 {
+		return fmt.Errorf("GAS_BUMP_AFTER, GAS_BUMP_MAX and GAS_BUMP_UNDERPRICED_RETRIES must not be negative")
+	}
+	if percent < 10 {
+		return fmt.Errorf("GAS_BUMP_PERCENT must be at least 10")
+	}
+
+	gasBump = gasBumpConfig{after: after, percent: int64(percent), maxBumps: maxBumps, underpricedRetries: underpricedRetries}
+	return nil
+}
+
+// errFeeCapReached means a replacement would need fees above MAX_FEE_GWEI,
+// so there is no point bumping the transaction again.
+var errFeeCapReached = errors.New("bumped fees exceed MAX_FEE_GWEI")
+
+// waitMinedWithBumps waits for tx like waitForTransaction, but each time it
+// goes GAS_BUMP_AFTER without a receipt it re-signs the same nonce with
+// bumped fees and sends the replacement. Any of the versions sent may end up
+// mined, so every poll checks all of them against the same confirmation
+// rules. Once one of them is in a block it only needs more confirmations,
+// and no more replacements are sent unless a reorg drops it again.
+func waitMinedWithBumps(net *network, tx *types.Transaction) (*types.Receipt, error) {
+	deadline := time.Now().Add(5 * time.Minute)
+	sent := []*types.Transaction{tx}
+	hashes := []common.Hash{tx.Hash()}
+	watch := newReceiptWatch(net)
+
+	for bumps := 0; ; bumps++ {
+		wait := time.Until(deadline)
+		if bumps < gasBump.maxBumps && gasBump.after < wait {
+			wait = gasBump.after
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), wait)
+		receipt, err := watch.wait(ctx, hashes)
+		cancel()
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if bumps >= gasBump.maxBumps || !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("timeout waiting for transaction")
+		}
+		if len(watch.seen) > 0 {
+			bumps--
+			continue
+		}
+
+		latest := sent[len(sent)-1]
+		replacement, err := sendBump(net, latest, bumps+1)
+		if replacement != nil {
+			sent = append(sent, replacement)
+			hashes = append(hashes, replacement.Hash())
+		}
+		if err != nil {
+			log.Printf("Tx %s stuck at nonce %d; bump %d/%d failed: %v", latest.Hash().Hex(), latest.Nonce(), bumps+1, gasBump.maxBumps, err)
+			if errors.Is(err, errFeeCapReached) {
+				bumps = gasBump.maxBumps - 1
+			}
+		}
+	}
+}
+
+// sendBump signs and sends a fee-bumped replacement for tx. A node that
+// rejects it as underpriced wants a bigger bump than GAS_BUMP_PERCENT, so the
+// percentage is raised by another GAS_BUMP_PERCENT and the replacement
+// re-signed, up to GAS_BUMP_UNDERPRICED_RETRIES times. A send that fails
+// without the node clearly rejecting it may still have reached the mempool,
+// so that replacement is returned along with the error and watched like the
+// others.
+func sendBump(net *network, tx *types.Transaction, bump int) (*types.Transaction, error) {
+	percent := gasBump.percent
+	for attempt := 0; ; attempt++ {
+		replacement, err := bumpTransaction(net, tx, percent)
+		if err != nil {
+			return nil, err
+		}
+		err = net.client.SendTransaction(context.Background(), replacement)
+		if err == nil {
+			log.Printf("Tx %s stuck at nonce %d; bump %d/%d replaced it with %s at %d%% higher fees",
+				tx.Hash().Hex(), tx.Nonce(), bump, gasBump.maxBumps, replacement.Hash().Hex(), percent)
+			return replacement, nil
+		}
+		if !isBroadcastRejection(err) {
+			return replacement, fmt.Errorf("failed to send replacement %s, treating it as possibly sent: %v", replacement.Hash().Hex(), err)
+		}
+		if !isUnderpricedError(err) || attempt >= gasBump.underpricedRetries {
+			return nil, fmt.Errorf("failed to send replacement: %v", err)
+		}
+		log.Printf("Tx %s stuck at nonce %d; replacement at %d%% higher fees rejected as underpriced (retry %d/%d): %v",
+			tx.Hash().Hex(), tx.Nonce(), percent, attempt+1, gasBump.underpricedRetries, err)
+		percent += gasBump.percent
+	}
+}
+
+func isUnderpricedError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "underpriced")
+}
+
+// bumpTransaction re-signs tx with the same nonce, gas limit and call data
+// and its fees raised by percent, refusing to exceed MAX_FEE_GWEI.
+func bumpTransaction(net *network, tx *types.Transaction, percent int64) (*types.Transaction, error) {
+	var inner types.TxData
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		feeCap := bumpFee(tx.GasFeeCap(), percent)
+		if feeCap.Cmp(fees.maxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w: fee cap %s gwei", errFeeCapReached, formatUnits(feeCap, 9))
+		}
+		inner = &types.DynamicFeeTx{
+			ChainID:   tx.ChainId(),
+			Nonce:     tx.Nonce(),
+			GasTipCap: bumpFee(tx.GasTipCap(), percent),
+			GasFeeCap: feeCap,
+			Gas:       tx.Gas(),
+			To:        tx.To(),
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		}
+	case types.LegacyTxType:
+		gasPrice := bumpFee(tx.GasPrice(), percent)
+		if gasPrice.Cmp(fees.maxFeePerGas) > 0 {
+			return nil, fmt.Errorf("%w: gas price %s gwei", errFeeCapReached, formatUnits(gasPrice, 9))
+		}
+		inner = &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", tx.Type())
+	}
+
+	auth, err := newTransactor(net)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %v", err)
+	}
+	return auth.Signer(auth.From, types.NewTx(inner))
+}
+
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// signedMint returns a dynamic-fee transaction from net's signer with a
+// 1 gwei fee cap and tip.
+func signedMint(t *testing.T, net *network) *types.Transaction {
+	t.Helper()
+	auth, err := newTransactor(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := auth.Signer(auth.From, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   net.chainID,
+		Nonce:     5,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(1e9),
+		Gas:       100000,
+		To:        &testContractAddr,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func decodeRawTx(t *testing.T, params []json.RawMessage) *types.Transaction {
+	t.Helper()
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(params[0], &raw); err != nil {
+		t.Fatal(err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestSendBumpRaisesPercentWhenUnderpriced(t *testing.T) {
+	loadTestFeeConfig(t)
+
+	tests := []struct {
+		name        string
+		rejections  int
+		retries     int
+		wantFeeCaps []int64
+		wantErr     bool
+	}{
+		{name: "accepted first time", wantFeeCaps: []int64{1.15e9}, retries: 3},
+		{name: "underpriced twice", rejections: 2, retries: 3, wantFeeCaps: []int64{1.15e9, 1.3e9, 1.45e9}},
+		{name: "retries exhausted", rejections: 5, retries: 2, wantFeeCaps: []int64{1.15e9, 1.3e9, 1.45e9}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGlobal(t, &gasBump, gasBumpConfig{percent: 15, maxBumps: 3, underpricedRetries: tt.retries})
+
+			var mu sync.Mutex
+			var sent []*types.Transaction
+			net, _ := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+				"eth_sendRawTransaction": func(params []json.RawMessage) (any, error) {
+					tx := decodeRawTx(t, params)
+					mu.Lock()
+					defer mu.Unlock()
+					sent = append(sent, tx)
+					if len(sent) <= tt.rejections {
+						return nil, errors.New("replacement transaction underpriced")
+					}
+					return tx.Hash(), nil
+				},
+			})
+			original := signedMint(t, net)
+
+			replacement, err := sendBump(net, original, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sendBump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(sent) != len(tt.wantFeeCaps) {
+				t.Fatalf("sent %d replacements, want %d", len(sent), len(tt.wantFeeCaps))
+			}
+			for i, tx := range sent {
+				if tx.Nonce() != original.Nonce() || tx.GasFeeCap().Int64() != tt.wantFeeCaps[i] || tx.GasTipCap().Int64() != tt.wantFeeCaps[i] {
+					t.Errorf("replacement %d: nonce %d fee cap %s tip %s, want nonce %d fees %d",
+						i, tx.Nonce(), tx.GasFeeCap(), tx.GasTipCap(), original.Nonce(), tt.wantFeeCaps[i])
+				}
+			}
+			if !tt.wantErr && replacement.Hash() != sent[len(sent)-1].Hash() {
+				t.Errorf("sendBump() returned %s, want the accepted replacement", replacement.Hash().Hex())
+			}
+		})
+	}
+}
+
+func TestWaitMinedWithBumpsWaitsForConfirmationsOfOlderVersion(t *testing.T) {
+	loadTestFeeConfig(t)
+	setGlobal(t, &gasBump, gasBumpConfig{after: 20 * time.Millisecond, percent: 15, maxBumps: 3})
+	setGlobal(t, &requiredConfirmations, 3)
+
+	var mu sync.Mutex
+	var original common.Hash
+	var bumped bool
+	head := uint64(10)
+	net, node := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_sendRawTransaction": func(params []json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			bumped = true
+			return decodeRawTx(t, params).Hash(), nil
+		},
+		// Only the original is ever mined, at block 10, once a replacement has
+		// been sent, and the head moves on by one block each time it is read.
+		"eth_getTransactionReceipt": func(params []json.RawMessage) (any, error) {
+			var hash common.Hash
+			json.Unmarshal(params[0], &hash)
+			mu.Lock()
+			defer mu.Unlock()
+			if hash != original || !bumped {
+				return json.RawMessage("null"), nil
+			}
+			return &types.Receipt{
+				Type:        types.DynamicFeeTxType,
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      hash,
+				BlockHash:   common.HexToHash("0x10"),
+				BlockNumber: big.NewInt(10),
+				Logs:        []*types.Log{},
+			}, nil
+		},
+		"eth_blockNumber": func([]json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			head++
+			return hexutil.Uint64(head), nil
+		},
+	})
+	tx := signedMint(t, net)
+	mu.Lock()
+	original = tx.Hash()
+	mu.Unlock()
+
+	receipt, err := waitMinedWithBumps(net, tx)
+	if err != nil {
+		t.Fatalf("waitMinedWithBumps() error = %v", err)
+	}
+	if receipt.TxHash != original {
+		t.Errorf("receipt for %s, want the original %s", receipt.TxHash.Hex(), original.Hex())
+	}
+	if calls := node.callCount("eth_blockNumber"); calls < 2 {
+		t.Errorf("head read %d times; the older version was returned before it had %d confirmations", calls, requiredConfirmations)
+	}
+	if sends := node.callCount("eth_sendRawTransaction"); sends != 1 {
+		t.Errorf("sent %d replacements, want 1: none once the original was in a block", sends)
+	}
+}
+
+func TestWaitMinedWithBumpsSkipsBumpWhileConfirming(t *testing.T) {
+	loadTestFeeConfig(t)
+	setGlobal(t, &gasBump, gasBumpConfig{after: 20 * time.Millisecond, percent: 15, maxBumps: 3})
+	setGlobal(t, &requiredConfirmations, 3)
+
+	var mu sync.Mutex
+	reads := 0
+	net, node := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_sendRawTransaction": func(params []json.RawMessage) (any, error) {
+			return decodeRawTx(t, params).Hash(), nil
+		},
+		// The original is mined at block 10 from the start.
+		"eth_getTransactionReceipt": func(params []json.RawMessage) (any, error) {
+			var hash common.Hash
+			json.Unmarshal(params[0], &hash)
+			return &types.Receipt{
+				Type:        types.DynamicFeeTxType,
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      hash,
+				BlockHash:   common.HexToHash("0x10"),
+				BlockNumber: big.NewInt(10),
+				Logs:        []*types.Log{},
+			}, nil
+		},
+		// The head stays at block 10 for more bump intervals than
+		// GAS_BUMP_MAX before reaching the third confirmation.
+		"eth_blockNumber": func([]json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reads++
+			if reads <= 5 {
+				return hexutil.Uint64(10), nil
+			}
+			return hexutil.Uint64(12), nil
+		},
+	})
+	tx := signedMint(t, net)
+
+	receipt, err := waitMinedWithBumps(net, tx)
+	if err != nil {
+		t.Fatalf("waitMinedWithBumps() error = %v", err)
+	}
+	if receipt.TxHash != tx.Hash() {
+		t.Errorf("receipt for %s, want the original %s", receipt.TxHash.Hex(), tx.Hash().Hex())
+	}
+	if sends := node.callCount("eth_sendRawTransaction"); sends != 0 {
+		t.Errorf("sent %d replacements for a transaction already in a block", sends)
+	}
+}
+
+func TestWaitMinedWithBumpsWatchesAmbiguousReplacement(t *testing.T) {
+	loadTestFeeConfig(t)
+	setGlobal(t, &gasBump, gasBumpConfig{after: 20 * time.Millisecond, percent: 15, maxBumps: 1})
+
+	var mu sync.Mutex
+	var replacement common.Hash
+	net, _ := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+		// The node takes the replacement but the response is lost.
+		"eth_sendRawTransaction": func(params []json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			replacement = decodeRawTx(t, params).Hash()
+			return nil, errors.New("connection reset by peer")
+		},
+		"eth_getTransactionReceipt": func(params []json.RawMessage) (any, error) {
+			var hash common.Hash
+			json.Unmarshal(params[0], &hash)
+			mu.Lock()
+			defer mu.Unlock()
+			if hash != replacement {
+				return json.RawMessage("null"), nil
+			}
+			return &types.Receipt{
+				Type:        types.DynamicFeeTxType,
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      hash,
+				BlockHash:   common.HexToHash("0x10"),
+				BlockNumber: big.NewInt(10),
+				Logs:        []*types.Log{},
+			}, nil
+		},
+	})
+	tx := signedMint(t, net)
+
+	receipt, err := waitMinedWithBumps(net, tx)
+	if err != nil {
+		t.Fatalf("waitMinedWithBumps() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if receipt.TxHash != replacement {
+		t.Errorf("receipt for %s, want the replacement %s", receipt.TxHash.Hex(), replacement.Hex())
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// maxGasBalanceFraction caps a single mint's worst-case gas cost to this
+// fraction of the deployer's balance. Zero disables the check.
+var maxGasBalanceFraction float64
+
+func loadGasGuardConfig() error {
+	fraction, err := envFloat("MAX_GAS_BALANCE_FRACTION", 0)
+	if err != nil {
+		return err
+	}
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("MAX_GAS_BALANCE_FRACTION must be between 0 and 1")
+	}
+	maxGasBalanceFraction = fraction
+	return nil
+}
+
+func projectedGasCost(auth *bind.TransactOpts) *big.Int {
+	price := auth.GasPrice
+	if auth.GasFeeCap != nil {
+		price = auth.GasFeeCap
+	}
+	if price == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(price, new(big.Int).SetUint64(auth.GasLimit))
+}
+
+// checkGasHeadroom returns the projected cost and current balance, and
+// whether the mint is within the configured share of the balance.
+func checkGasHeadroom(net *network, auth *bind.TransactOpts) (*big.Int, *big.Int, bool, error) {
+	cost := projectedGasCost(auth)
+	if maxGasBalanceFraction == 0 {
+		return cost, nil, true, nil
+	}
+
+	balance, err := net.client.BalanceAt(context.Background(), net.from, nil)
+	if err != nil {
+		return cost, nil, false, fmt.Errorf("failed to read deployer balance: %v", err)
+	}
+
+	allowed, _ := new(big.Float).Mul(new(big.Float).SetInt(balance), big.NewFloat(maxGasBalanceFraction)).Int(nil)
+	return cost, balance, cost.Cmp(allowed) <= 0, nil
+}
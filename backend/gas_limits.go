@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const fallbackGasLimit = uint64(300000)
+
+var gasLimits = map[string]uint64{
+	"mint":         300000,
+	"mint_secure":  300000,
+	"transfer":     100000,
+	"transferFrom": 120000,
+	"approve":      80000,
+}
+
+// loadGasLimits applies GAS_LIMITS, a comma-separated list of method=gas
+// pairs such as "mint_secure=250000,approve=60000", on top of the defaults.
+// Methods must exist in the token ABI.
+func loadGasLimits() error {
+	value := strings.TrimSpace(os.Getenv("GAS_LIMITS"))
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return fmt.Errorf("failed to load contract ABI: %v", err)
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		method, limitValue, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("invalid GAS_LIMITS entry %q, expected method=gas", entry)
+		}
+		method = strings.TrimSpace(method)
+		if _, exists := parsed.Methods[method]; !exists {
+			return fmt.Errorf("GAS_LIMITS references unknown method %q", method)
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(limitValue), 10, 64)
+		if err != nil || limit < 21000 || limit > 30000000 {
+			return fmt.Errorf("invalid gas limit %q for %s", limitValue, method)
+		}
+		gasLimits[method] = limit
+	}
+	return nil
+}
+
+func gasLimitFor(method string) uint64 {
+	if limit, ok := gasLimits[method]; ok {
+		return limit
+	}
+	return fallbackGasLimit
+}
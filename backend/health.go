@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type HealthResponse struct {
+	Status      string `json:"status"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	blockNumber, err := client.BlockNumber(ctx)
+	if err != nil {
+		respondWithJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Message: "Ethereum node unreachable"})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, HealthResponse{Status: "ok", BlockNumber: blockNumber})
+}
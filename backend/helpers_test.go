@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var (
+	testContractAddr = common.HexToAddress("0xC0C0C0C0C0C0C0C0C0C0C0C0C0C0C0C0C0C0C0C0")
+	testRecipient    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+)
+
+// keepGlobal restores the package-level variable at target to its current
+// value when the test finishes, so tests that change configuration globals
+// do not depend on the order they run in.
+func keepGlobal[T any](t *testing.T, target *T) {
+	t.Helper()
+	old := *target
+	t.Cleanup(func() { *target = old })
+}
+
+// setGlobal sets the package-level variable at target for the rest of the
+// test.
+func setGlobal[T any](t *testing.T, target *T, value T) {
+	t.Helper()
+	keepGlobal(t, target)
+	*target = value
+}
+
+// loadTestFeeConfig loads the default fee settings for the rest of the test.
+func loadTestFeeConfig(t *testing.T) {
+	t.Helper()
+	keepGlobal(t, &fees)
+	if err := loadFeeConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stubBroadcaster records broadcasts and answers WaitMined from receipt,
+// or with a successful receipt when receipt is nil.
+type stubBroadcaster struct {
+	mu           sync.Mutex
+	broadcast    func(tx *types.Transaction) error
+	receipt      func(tx *types.Transaction) (*types.Receipt, error)
+	broadcastTxs []*types.Transaction
+}
+
+func (b *stubBroadcaster) Broadcast(_ context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	b.broadcastTxs = append(b.broadcastTxs, tx)
+	b.mu.Unlock()
+	if b.broadcast != nil {
+		return b.broadcast(tx)
+	}
+	return nil
+}
+
+func (b *stubBroadcaster) WaitMined(tx *types.Transaction) (*types.Receipt, error) {
+	if b.receipt != nil {
+		return b.receipt(tx)
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: big.NewInt(10)}, nil
+}
+
+func (b *stubBroadcaster) sent() []*types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*types.Transaction(nil), b.broadcastTxs...)
+}
+
+// fixedNonceSource reports the same pending nonce on every call.
+type fixedNonceSource struct {
+	mu    sync.Mutex
+	nonce uint64
+	calls int
+}
+
+func (s *fixedNonceSource) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.nonce, nil
+}
+
+// newTestNetwork returns a network bound to testContractAddr with a fresh
+// local key and no node connection.
+func newTestNetwork(t *testing.T) *network {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := NewToken(testContractAddr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := newLocalKeySigner(key)
+	return &network{
+		name:         defaultNetworkName,
+		chainID:      big.NewInt(1337),
+		contract:     token,
+		contractAddr: testContractAddr,
+		signer:       signer,
+		from:         signer.Address(),
+	}
+}
+
+// transferLog builds a Transfer log emitted by contract.
+func transferLog(t *testing.T, contract, from, to common.Address, value int64) *types.Log {
+	t.Helper()
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := parsed.Events["Transfer"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Log{
+		Address: contract,
+		Topics:  []common.Hash{event.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    data,
+	}
+}
+
+// fakeNode is a JSON-RPC server answering the methods registered in
+// handlers; anything else fails with "method not found".
+type fakeNode struct {
+	mu       sync.Mutex
+	handlers map[string]func(params []json.RawMessage) (any, error)
+	calls    map[string]int
+}
+
+type fakeRPCRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type fakeRPCResponse struct {
+	Version string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id"`
+	Result  any              `json:"result,omitempty"`
+	Error   *rpcErrorPayload `json:"error,omitempty"`
+}
+
+// newFakeNode starts a fake node and returns a client connected to it.
+func newFakeNode(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error)) (*fakeNode, *ethclient.Client) {
+	t.Helper()
+	node := &fakeNode{handlers: handlers, calls: make(map[string]int)}
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return node, client
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	body = bytes.TrimSpace(body)
+
+	var reqs []fakeRPCRequest
+	batch := len(body) > 0 && body[0] == '['
+	if batch {
+		json.Unmarshal(body, &reqs)
+	} else {
+		var req fakeRPCRequest
+		json.Unmarshal(body, &req)
+		reqs = []fakeRPCRequest{req}
+	}
+
+	resps := make([]fakeRPCResponse, len(reqs))
+	for i, req := range reqs {
+		resps[i] = n.handle(req)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(resps)
+	} else {
+		json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+func (n *fakeNode) handle(req fakeRPCRequest) fakeRPCResponse {
+	n.mu.Lock()
+	n.calls[req.Method]++
+	handler := n.handlers[req.Method]
+	n.mu.Unlock()
+
+	resp := fakeRPCResponse{Version: "2.0", ID: req.ID}
+	if handler == nil {
+		resp.Error = &rpcErrorPayload{Code: -32601, Message: "the method " + req.Method + " does not exist/is not available"}
+		return resp
+	}
+	result, err := handler(req.Params)
+	if err != nil {
+		var rpcErr *rpcErrorPayload
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcErrorPayload{Code: -32000, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+func (n *fakeNode) callCount(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls[method]
+}
+
+// newNodeNetwork is newTestNetwork with its client and token binding
+// connected to a fake node.
+func newNodeNetwork(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error)) (*network, *fakeNode) {
+	t.Helper()
+	net := newTestNetwork(t)
+	node, client := newFakeNode(t, handlers)
+	token, err := NewToken(testContractAddr, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.client = client
+	net.contract = token
+	return net, node
+}
+
+// callBlock returns the block number an eth_call was made at, or nil for
+// "latest"/"pending".
+func callBlock(params []json.RawMessage) *big.Int {
+	if len(params) < 2 {
+		return nil
+	}
+	var tag string
+	if json.Unmarshal(params[1], &tag) != nil {
+		return nil
+	}
+	block, err := hexutil.DecodeBig(tag)
+	if err != nil {
+		return nil
+	}
+	return block
+}
+
+// encodeUint256 ABI-encodes v as an eth_call result.
+func encodeUint256(v *big.Int) string {
+	return hexutil.Encode(common.LeftPadBytes(v.Bytes(), 32))
+}
+
+// mintNodeHandlers answers the RPCs made on the mint path: the pending-state
+// simulation, gas estimate and fee history.
+func mintNodeHandlers() map[string]func(params []json.RawMessage) (any, error) {
+	return map[string]func(params []json.RawMessage) (any, error){
+		"eth_call":        func([]json.RawMessage) (any, error) { return "0x", nil },
+		"eth_estimateGas": func([]json.RawMessage) (any, error) { return "0x186a0", nil },
+		"eth_feeHistory": func([]json.RawMessage) (any, error) {
+			return map[string]any{
+				"oldestBlock":   "0x1",
+				"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00"},
+				"gasUsedRatio":  []float64{0.5},
+				"reward":        [][]string{{"0x3b9aca00"}},
+			}, nil
+		},
+	}
+}
+
+// newMintTestNetwork makes a fake-node network the primary and only network,
+// with a stub broadcaster and a nonce manager starting at startNonce.
+func newMintTestNetwork(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error), startNonce uint64) (*network, *stubBroadcaster) {
+	t.Helper()
+	loadTestFeeConfig(t)
+	setGlobal(t, &tokenDecimals, 18)
+
+	net, _ := newNodeNetwork(t, handlers)
+	net.nonces = NewNonceManager(&fixedNonceSource{nonce: startNonce}, net.from)
+	broadcaster := &stubBroadcaster{}
+	net.broadcaster = broadcaster
+
+	setGlobal(t, &networks, map[string]*network{net.name: net})
+	setGlobal(t, &primaryNetwork, net)
+	return net, broadcaster
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+)
+
+// MintRecord is one mint attempt. Seq is assigned in the order records are
+// created and is the pagination cursor for GET /mints.
+type MintRecord struct {
+	ID          string    `json:"id"`
+	Seq         uint64    `json:"seq"`
+	TxHash      string    `json:"txHash,omitempty"`
+	Network     string    `json:"network,omitempty"`
+	Company     string    `json:"company"`
+	Sales       string    `json:"sales,omitempty"`
+	Amount      string    `json:"amount"`
+	Status      string    `json:"status"`
+	BlockNumber uint64    `json:"blockNumber,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type MintFilter struct {
+	Company *common.Address
+	Status  string
+	From    time.Time
+	To      time.Time
+	Before  uint64
+	Limit   int
+}
+
+// MintStore persists the mint audit trail. Save inserts or replaces the
+// record with the same ID; List returns matches newest first, starting below
+// filter.Before.
+type MintStore interface {
+	Save(record MintRecord) error
+	GetByTxHash(txHash common.Hash) (MintRecord, bool, error)
+	List(filter MintFilter) ([]MintRecord, error)
+}
+
+type MintHistoryResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Mints   []MintRecord `json:"mints"`
+	Next    uint64       `json:"next,omitempty"`
+}
+
+var mintHistory MintStore
+
+// fileMintStore appends every version of a record to a JSON-lines file and
+// keeps the latest version of each in memory. Replaying the file on start-up
+// restores the index, so the file is the only state; it is then compacted to
+// the latest versions so it does not grow with every status update.
+//
+// It stands in for a SQLite or Postgres store for now: the module has no
+// database driver dependency, and adding one (cgo for mattn/go-sqlite3, or
+// a pure-Go driver and its dependency tree) still needs sign-off. A SQL
+// store only has to implement MintStore and be chosen in loadMintHistory.
+// Until then the whole history is held in memory, which suits a single
+// instance with a modest mint volume.
+type fileMintStore struct {
+	mu      sync.RWMutex
+	file    *jsonlFile[MintRecord]
+	records map[string]*MintRecord
+	byHash  map[common.Hash]string
+	lastSeq uint64
+}
+
+func openFileMintStore(path string) (*fileMintStore, error) {
+	store := &fileMintStore{
+		records: make(map[string]*MintRecord),
+		byHash:  make(map[common.Hash]string),
+	}
+	file, err := openJSONLFile(path, "mint history", store.index)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make([]MintRecord, 0, len(store.records))
+	for _, record := range store.records {
+		latest = append(latest, *record)
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].Seq < latest[j].Seq })
+	if err := file.compact(latest); err != nil {
+		file.close()
+		return nil, err
+	}
+	store.file = file
+	return store, nil
+}
+
+// index makes record the latest version of its ID. A new ID gets the next
+// Seq; history written before Seq existed is numbered in file order.
+func (s *fileMintStore) index(record MintRecord) {
+	if existing, ok := s.records[record.ID]; ok {
+		record.Seq = existing.Seq
+	} else if record.Seq == 0 {
+		record.Seq = s.lastSeq + 1
+	}
+	s.lastSeq = max(s.lastSeq, record.Seq)
+	s.records[record.ID] = &record
+	if record.TxHash != "" {
+		s.byHash[common.HexToHash(record.TxHash)] = record.ID
+	}
+}
+
+func (s *fileMintStore) Save(record MintRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.ID]; ok {
+		record.Seq = existing.Seq
+	} else {
+		record.Seq = s.lastSeq + 1
+	}
+	if err := s.file.append(record); err != nil {
+		return err
+	}
+	s.index(record)
+	return nil
+}
+
+func (s *fileMintStore) GetByTxHash(txHash common.Hash) (MintRecord, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byHash[txHash]
+	if !ok {
+		return MintRecord{}, false, nil
+	}
+	return *s.records[id], true, nil
+}
+
+func (s *fileMintStore) List(filter MintFilter) ([]MintRecord, error) {
+	s.mu.RLock()
+	matches := make([]MintRecord, 0)
+	for _, record := range s.records {
+		if filter.Before != 0 && record.Seq >= filter.Before {
+			continue
+		}
+		if filter.Company != nil && record.Company != filter.Company.Hex() {
+			continue
+		}
+		if filter.Status != "" && record.Status != filter.Status {
+			continue
+		}
+		if !filter.From.IsZero() && record.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !record.CreatedAt.Before(filter.To) {
+			continue
+		}
+		matches = append(matches, *record)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Seq > matches[j].Seq })
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, nil
+}
+
+func loadMintHistory() error {
+	path := os.Getenv("MINT_HISTORY_FILE")
+	if path == "" {
+		return nil
+	}
+
+	store, err := openFileMintStore(path)
+	if err != nil {
+		return err
+	}
+	mintHistory = store
+	return nil
+}
+
+// recordMint adds a mint attempt to the history, including ones rejected
+// before broadcast. Recording failures are only logged: by the time this runs
+// the transaction may already be on-chain, so failing the request would
+// invite a duplicate retry.
+func recordMint(source string, net *network, company common.Address, sales decimalAmount, amount *big.Int, resp MintResponse) {
+	if mintHistory == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	record := MintRecord{
+		ID:          newJobID(),
+		TxHash:      resp.TxHash,
+		Network:     net.label(),
+		Company:     company.Hex(),
+		Sales:       string(sales),
+		Amount:      amount.String(),
+		Status:      historyStatus(resp),
+		BlockNumber: resp.BlockNumber,
+		Message:     resp.Message,
+		Source:      source,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := mintHistory.Save(record); err != nil {
+		log.Printf("Failed to record mint to %s: %v", company.Hex(), err)
+	}
+}
+
+// updateMintHistory records the final outcome of a mint that was recorded
+// as pending when it was broadcast.
+func updateMintHistory(txHash common.Hash, result MintResponse) {
+	if mintHistory == nil {
+		return
+	}
+
+	record, ok, err := mintHistory.GetByTxHash(txHash)
+	if err != nil || !ok {
+		log.Printf("Mint %s finished but has no history record (err=%v)", txHash.Hex(), err)
+		return
+	}
+	if result.TxHash != "" {
+		record.TxHash = result.TxHash
+	}
+	record.Status = historyStatus(result)
+	record.BlockNumber = result.BlockNumber
+	record.Message = result.Message
+	record.UpdatedAt = time.Now().UTC()
+	if err := mintHistory.Save(record); err != nil {
+		log.Printf("Failed to update mint history for %s: %v", txHash.Hex(), err)
+	}
+}
+
+func historyStatus(resp MintResponse) string {
+	switch {
+	case resp.Status == txStatusPending, resp.Status == txStatusUnknown:
+		return resp.Status
+	case resp.Success:
+		return txStatusConfirmed
+	default:
+		return txStatusFailed
+	}
+}
+
+// listMintsHandler lists recorded mints newest first. When more remain, Next
+// is the value to pass as ?before= for the following page.
+func listMintsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := MintFilter{Status: query.Get("status"), Limit: 100}
+
+	if company := query.Get("company"); company != "" {
+		if !common.IsHexAddress(company) {
+			respondWithError(w, http.StatusBadRequest, "Invalid company address")
+			return
+		}
+		address := common.HexToAddress(company)
+		filter.Company = &address
+	}
+	switch filter.Status {
+	case "", txStatusPending, txStatusConfirmed, txStatusFailed, txStatusUnknown:
+	default:
+		respondWithError(w, http.StatusBadRequest, "status must be pending, confirmed, failed or unknown")
+		return
+	}
+
+	var err error
+	if filter.From, err = parseHistoryTime(query.Get("from")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid from: "+err.Error())
+		return
+	}
+	if filter.To, err = parseHistoryTime(query.Get("to")); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid to: "+err.Error())
+		return
+	}
+	if value := query.Get("before"); value != "" {
+		filter.Before, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "before must be a non-negative integer")
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit <= 0 || filter.Limit > 1000 {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+	}
+
+	// One extra record tells whether another page exists.
+	limit := filter.Limit
+	filter.Limit++
+	mints, err := mintHistory.List(filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read mint history: %v", err))
+		return
+	}
+	resp := MintHistoryResponse{Success: true, Mints: mints}
+	if len(mints) > limit {
+		resp.Mints = mints[:limit]
+		resp.Next = resp.Mints[limit-1].Seq
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// parseHistoryTime accepts RFC 3339 timestamps or plain dates (midnight UTC).
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if !strings.Contains(value, "T") {
+		return time.Parse(time.DateOnly, value)
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func getMintHandler(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["txHash"]
+	if !isValidTxHash(hash) {
+		respondWithError(w, http.StatusBadRequest, "Invalid transaction hash: expected 0x-prefixed 32-byte hex")
+		return
+	}
+
+	record, ok, err := mintHistory.GetByTxHash(common.HexToHash(hash))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to read mint history: %v", err))
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Mint not found")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, record)
+}
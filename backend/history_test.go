@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileMintStoreCompactsOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mints.jsonl")
+	store, err := openFileMintStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for i := 0; i < 3; i++ {
+		record := MintRecord{ID: fmt.Sprintf("id%d", i), TxHash: fmt.Sprintf("0x%064x", i+1), Status: txStatusPending, CreatedAt: now}
+		store.Save(record)
+		record.Status = txStatusConfirmed
+		store.Save(record)
+	}
+	store.file.close()
+
+	reopened, err := openFileMintStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.file.close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("compacted history has %d lines, want 3", lines)
+	}
+	mints, _ := reopened.List(MintFilter{})
+	for i, mint := range mints {
+		if mint.Status != txStatusConfirmed || mint.Seq != uint64(3-i) {
+			t.Errorf("mint %d = seq %d status %s, want seq %d confirmed", i, mint.Seq, mint.Status, 3-i)
+		}
+	}
+
+	// A record saved after the reopen continues the sequence.
+	reopened.Save(MintRecord{ID: "id3", CreatedAt: now})
+	if mints, _ := reopened.List(MintFilter{Limit: 1}); mints[0].ID != "id3" || mints[0].Seq != 4 {
+		t.Errorf("newest mint = %+v, want id3 with seq 4", mints[0])
+	}
+}
+
+func TestFileMintStoreNumbersLegacyHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mints.jsonl")
+	legacy := `{"id":"a","status":"pending","createdAt":"2026-01-01T00:00:00Z"}
+{"id":"b","status":"pending","createdAt":"2026-01-01T00:00:00Z"}
+{"id":"a","status":"confirmed","createdAt":"2026-01-01T00:00:00Z"}
+`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := openFileMintStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.file.close()
+
+	mints, _ := store.List(MintFilter{})
+	if len(mints) != 2 || mints[0].ID != "b" || mints[0].Seq != 2 || mints[1].ID != "a" || mints[1].Seq != 1 || mints[1].Status != txStatusConfirmed {
+		t.Errorf("mints = %+v, want b (seq 2) then confirmed a (seq 1)", mints)
+	}
+}
+
+func TestListMintsHandlerPages(t *testing.T) {
+	store, err := openFileMintStore(filepath.Join(t.TempDir(), "mints.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.file.close()
+	setGlobal[MintStore](t, &mintHistory, store)
+
+	// Records share a timestamp, so only Seq gives a stable order. Four
+	// records fill exactly two pages, and the second must not offer a third.
+	now := time.Now().UTC()
+	for i := 0; i < 4; i++ {
+		store.Save(MintRecord{ID: fmt.Sprintf("id%d", i), Status: txStatusConfirmed, CreatedAt: now})
+	}
+
+	var seen []uint64
+	before := ""
+	pages := 0
+	for ; pages < 4; pages++ {
+		req := httptest.NewRequest(http.MethodGet, "/mints?limit=2"+before, nil)
+		w := httptest.NewRecorder()
+		listMintsHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		var resp MintHistoryResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, mint := range resp.Mints {
+			seen = append(seen, mint.Seq)
+		}
+		if resp.Next == 0 {
+			break
+		}
+		before = fmt.Sprintf("&before=%d", resp.Next)
+	}
+	if fmt.Sprint(seen) != "[4 3 2 1]" || pages != 1 {
+		t.Errorf("paged through seqs %v in %d pages, want [4 3 2 1] in 2", seen, pages+1)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/mints?before=-1", nil)
+	w := httptest.NewRecorder()
+	listMintsHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("before=-1: status = %d, want 400", w.Code)
+	}
+}
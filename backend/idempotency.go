@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// idempotencyEntry is one key and, once the mint has been broadcast, the
+// response that was sent for it. Status is 0 while the request is in flight;
+// Released records that the key was given up and may be used again.
+type idempotencyEntry struct {
+	Key         string        `json:"key"`
+	Fingerprint string        `json:"fingerprint,omitempty"`
+	Status      int           `json:"status,omitempty"`
+	Response    *MintResponse `json:"response,omitempty"`
+	Released    bool          `json:"released,omitempty"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// idempotencyStore remembers POST /mint results by Idempotency-Key for the
+// retention window. With IDEMPOTENCY_FILE set every change is appended to a
+// JSON-lines file, which is compacted to the live entries on start-up.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	file      *jsonlFile[idempotencyEntry]
+	retention time.Duration
+	entries   map[string]*idempotencyEntry
+}
+
+var idempotencyKeys *idempotencyStore
+
+func loadIdempotencyConfig() error {
+	retention, err := envDuration("IDEMPOTENCY_RETENTION", 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if retention <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_RETENTION must be positive")
+	}
+
+	store := &idempotencyStore{retention: retention, entries: make(map[string]*idempotencyEntry)}
+	if path := os.Getenv("IDEMPOTENCY_FILE"); path != "" {
+		if err := store.open(path); err != nil {
+			return err
+		}
+	}
+	idempotencyKeys = store
+	go runPruneLoop(retention, func() {
+		store.mu.Lock()
+		store.prune()
+		store.mu.Unlock()
+	})
+	return nil
+}
+
+// open replays the file, then rewrites it with only the unexpired entries.
+// Entries still in flight when the process stopped are kept as in flight:
+// their transaction may or may not have gone out, so retries get 409 until
+// the retention window passes rather than risking a second mint.
+func (s *idempotencyStore) open(path string) error {
+	file, err := openJSONLFile(path, "idempotency file", func(entry idempotencyEntry) {
+		if entry.Released {
+			delete(s.entries, entry.Key)
+			return
+		}
+		s.entries[entry.Key] = &entry
+	})
+	if err != nil {
+		return err
+	}
+	s.prune()
+
+	live := make([]idempotencyEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		live = append(live, *entry)
+	}
+	if err := file.compact(live); err != nil {
+		file.close()
+		return err
+	}
+	s.file = file
+	return nil
+}
+
+func (s *idempotencyStore) write(entry idempotencyEntry) {
+	if s.file == nil {
+		return
+	}
+	if err := s.file.append(entry); err != nil {
+		log.Printf("Failed to persist idempotency key: %v", err)
+	}
+}
+
+// claim reserves key for a new request. If the key is already known it
+// returns the existing entry instead, and reports false.
+func (s *idempotencyStore) claim(key, fingerprint string) (idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && time.Since(entry.CreatedAt) < s.retention {
+		return *entry, false
+	}
+	entry := &idempotencyEntry{Key: key, Fingerprint: fingerprint, CreatedAt: time.Now().UTC()}
+	s.entries[key] = entry
+	s.write(*entry)
+	return *entry, true
+}
+
+// complete stores the response for key. A mint that certainly never reached
+// the chain releases the key instead, so the caller's retry is a real
+// attempt rather than a replay of the failure.
+func (s *idempotencyStore) complete(key string, status int, resp MintResponse) {
+	if !resp.mayBeOnChain() {
+		s.release(key)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	entry.Status = status
+	entry.Response = &resp
+	s.write(*entry)
+}
+
+func (s *idempotencyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.write(idempotencyEntry{Key: key, Released: true, CreatedAt: time.Now().UTC()})
+	}
+}
+
+// prune drops entries older than the retention window. The file is only
+// compacted on start-up; expired lines there are skipped when replayed.
+func (s *idempotencyStore) prune() {
+	cutoff := time.Now().Add(-s.retention)
+	for key, entry := range s.entries {
+		if entry.CreatedAt.Before(cutoff) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// idempotencyKeyFor returns the request's Idempotency-Key (header first,
+// then the idempotencyKey field), scoped to the caller's API key so that two
+// clients cannot collide. It returns "" when the request has none.
+func idempotencyKeyFor(r *http.Request, req MintRequest) (string, error) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		key = req.IdempotencyKey
+	}
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", fmt.Errorf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+	}
+	for _, c := range key {
+		if c < 0x20 || c > 0x7e {
+			return "", fmt.Errorf("%s must be printable ASCII", idempotencyKeyHeader)
+		}
+	}
+
+	if caller, _ := r.Context().Value(apiKeyContextKey{}).(*apiKey); caller != nil {
+		key = caller.name + ":" + key
+	}
+	return key, nil
+}
+
+// mintFingerprint identifies what a request asks for, so a key reused for a
+// different mint is rejected instead of silently replaying the first one.
+func mintFingerprint(net *network, target common.Address, amount *big.Int) string {
+	sum := sha256.Sum256([]byte(net.name + "|" + target.Hex() + "|" + amount.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// claimIdempotencyKey reserves key for this request. When the key has been
+// seen before it writes the stored response, a 409 while the first request
+// is still in flight, or a 422 if the payload differs, and returns false.
+func claimIdempotencyKey(w http.ResponseWriter, key, fingerprint string) bool {
+	entry, claimed := idempotencyKeys.claim(key, fingerprint)
+	if claimed {
+		return true
+	}
+
+	switch {
+	case entry.Fingerprint != fingerprint:
+		respondWithError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different mint request")
+	case entry.Response == nil:
+		w.Header().Set("Retry-After", "1")
+		respondWithError(w, http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+	default:
+		w.Header().Set(idempotencyReplayedHeader, "true")
+		respondWithJSON(w, entry.Status, *entry.Response)
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+)
+
+type InfoResponse struct {
+	ContractAddress string   `json:"contractAddress"`
+	ChainID         string   `json:"chainId"`
+	Minter          string   `json:"minter"`
+	Decimals        int      `json:"decimals"`
+	Features        []string `json:"features"`
+	Networks        []string `json:"networks,omitempty"`
+}
+
+func infoHandler(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, InfoResponse{
+		ContractAddress: contractAddr.Hex(),
+		ChainID:         connectedChainID.String(),
+		Minter:          fromAddress.Hex(),
+		Decimals:        tokenDecimals,
+		Features:        enabledFeatureList(),
+		Networks:        configuredNetworks(),
+	})
+}
+
+// configuredNetworks lists the names /mint accepts in its "network" field,
+// or nothing when only the primary network is configured.
+func configuredNetworks() []string {
+	if len(networks) <= 1 {
+		return nil
+	}
+	return networkNames()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// loadShedder is a coarse, service-wide safety valve that caps concurrent
+// requests and the overall request rate, independent of any per-client
+// limits, to protect the single signing key and the upstream node.
+type loadShedder struct {
+	slots chan struct{}
+	rate  *tokenBucket
+
+	shed     atomic.Int64
+	lastShed atomic.Int64
+}
+
+// tokenBucket allows rate requests per second on average with bursts of up
+// to burst. A zero rate allows everything.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), lastFill: time.Now()}
+}
+
+func (b *tokenBucket) allow() bool {
+	if b.rate == 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.lastFill).Seconds()*b.rate)
+	b.lastFill = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// retryAfter is the whole number of seconds until a token is next available
+// at the average rate.
+func (b *tokenBucket) retryAfter() int {
+	if b.rate > 0 && b.rate < 1 {
+		return int(math.Ceil(1 / b.rate))
+	}
+	return 1
+}
+
+var shedder *loadShedder
+
+func loadLoadShedderConfig() (*loadShedder, error) {
+	maxConcurrent, err := envInt("GLOBAL_MAX_CONCURRENT", 0)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := envFloat("GLOBAL_RATE_LIMIT", 0)
+	if err != nil {
+		return nil, err
+	}
+	burst, err := envInt("GLOBAL_RATE_BURST", int(math.Ceil(rate)))
+	if err != nil {
+		return nil, err
+	}
+	if maxConcurrent < 0 || rate < 0 || burst < 0 {
+		return nil, fmt.Errorf("global load limits must not be negative")
+	}
+	if maxConcurrent == 0 && rate == 0 {
+		return nil, nil
+	}
+	if rate > 0 && burst == 0 {
+		burst = 1
+	}
+
+	ls := &loadShedder{rate: newTokenBucket(rate, burst)}
+	if maxConcurrent > 0 {
+		ls.slots = make(chan struct{}, maxConcurrent)
+	}
+	return ls, nil
+}
+
+func (ls *loadShedder) reject(w http.ResponseWriter) {
+	ls.shed.Add(1)
+	ls.lastShed.Store(time.Now().UnixNano())
+
+	w.Header().Set("Retry-After", strconv.Itoa(ls.rate.retryAfter()))
+	respondWithError(w, http.StatusServiceUnavailable, "Service is overloaded, please retry later")
+}
+
+// shedding reports whether a request was shed within the last few seconds.
+func (ls *loadShedder) shedding() bool {
+	last := ls.lastShed.Load()
+	return last != 0 && time.Since(time.Unix(0, last)) < 10*time.Second
+}
+
+// middleware applies the global limits. Event streams only count against
+// the rate: they stay open indefinitely and are capped by EVENTS_MAX_STREAMS
+// instead of holding a concurrency slot.
+func (ls *loadShedder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route != nil && (route.GetName() == routeStats || route.GetName() == routeHealth) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !ls.rate.allow() {
+			ls.reject(w)
+			return
+		}
+
+		if ls.slots != nil && (route == nil || route.GetName() != routeEventStream) {
+			select {
+			case ls.slots <- struct{}{}:
+				defer func() { <-ls.slots }()
+			default:
+				ls.reject(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,43 +2,92 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
 
 type MintRequest struct {
-	Sales   float64 `json:"sales"`
-	Company string  `json:"company"`
+	Sales     decimalAmount `json:"sales"`
+	Company   string        `json:"company"`
+	AmountWei string        `json:"amountWei,omitempty"`
+	DryRun    bool          `json:"dryRun,omitempty"`
+	Network   string        `json:"network,omitempty"`
+
+	// IdempotencyKey is used when the Idempotency-Key header is absent.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 type MintResponse struct {
-	Success      bool   `json:"success"`
-	Message      string `json:"message"`
-	TxHash       string `json:"txHash,omitempty"`
-	BlockNumber  uint64 `json:"blockNumber,omitempty"`
-	AmountMinted string `json:"amountMinted,omitempty"`
+	Success              bool     `json:"success"`
+	Message              string   `json:"message"`
+	Network              string   `json:"network,omitempty"`
+	Errors               []string `json:"errors,omitempty"`
+	Status               string   `json:"status,omitempty"`
+	JobID                string   `json:"jobId,omitempty"`
+	TxHash               string   `json:"txHash,omitempty"`
+	OriginalTxHash       string   `json:"originalTxHash,omitempty"`
+	Nonce                *uint64  `json:"nonce,omitempty"`
+	BlockNumber          uint64   `json:"blockNumber,omitempty"`
+	AmountMinted         string   `json:"amountMinted,omitempty"`
+	AmountCredited       string   `json:"amountCredited,omitempty"`
+	AmountDifference     string   `json:"amountDifference,omitempty"`
+	AmountMismatch       bool     `json:"amountMismatch,omitempty"`
+	BalanceDelta         string   `json:"balanceDelta,omitempty"`
+	BalanceDeltaMismatch bool     `json:"balanceDeltaMismatch,omitempty"`
+	Simulated            bool     `json:"simulated,omitempty"`
+	EstimatedGas         uint64   `json:"estimatedGas,omitempty"`
+	GasUsed              uint64   `json:"gasUsed,omitempty"`
+	GasCost              string   `json:"gasCost,omitempty"`
+	GasCostFiat          *float64 `json:"gasCostFiat,omitempty"`
+	FiatCurrency         string   `json:"fiatCurrency,omitempty"`
+	CooldownRemaining    uint64   `json:"cooldownRemaining,omitempty"`
+	ProjectedGasCost     string   `json:"projectedGasCost,omitempty"`
+	DeployerBalance      string   `json:"deployerBalance,omitempty"`
+	CorrelationID        string   `json:"correlationId,omitempty"`
+	Signer               string   `json:"signer,omitempty"`
+	Signature            string   `json:"signature,omitempty"`
+
+	// rejected marks a failed mint whose transaction was signed, so TxHash
+	// is set, but which the node refused outright.
+	rejected bool
+}
+
+// mayBeOnChain reports whether the mint's transaction could still be mined:
+// it was broadcast, or the broadcast failed without ruling that out. Such a
+// mint keeps its cap reservation and idempotency key.
+func (r MintResponse) mayBeOnChain() bool {
+	return r.TxHash != "" && !r.rejected
 }
 
 var (
 	client       *ethclient.Client
-	privateKey   *ecdsa.PrivateKey
 	fromAddress  common.Address
 	contract     *Token
 	contractAddr common.Address
+
+	connectedChainID *big.Int
+
+	// syncConfirm is the default for whether /mint blocks until the receipt
+	// is available (up to the 5 minute wait timeout). With SYNC_CONFIRM=false,
+	// the default, /mint returns 202 right after broadcast with a pending
+	// status, which frees the connection and raises throughput but means
+	// callers only learn the outcome by polling GET /status/{hash}, and that
+	// outcome lives in memory on this instance only. Callers can override the
+	// default per request with ?wait=true or ?wait=false.
+	syncConfirm bool
 )
 
 func main() {
@@ -47,13 +96,60 @@ func main() {
 		log.Println("Warning: .env file not found - using environment variables")
 	}
 
+	if err := loadFeatureFlags(); err != nil {
+		log.Fatalf("Invalid feature configuration: %v", err)
+	}
+
 	if err := initEthereum(); err != nil {
 		log.Fatalf("Failed to initialize Ethereum client: %v", err)
 	}
-	defer client.Close()
+	defer func() {
+		for _, net := range networks {
+			net.client.Close()
+		}
+	}()
 
-	r := mux.NewRouter()
-	r.HandleFunc("/mint", mintTokensHandler).Methods("POST")
+	alertCfg, err := loadBalanceAlertConfig()
+	if err != nil {
+		log.Fatalf("Invalid balance alert configuration: %v", err)
+	}
+	if alertCfg != nil {
+		go monitorDeployerBalance(alertCfg)
+	}
+
+	scheduleCfg, err := loadScheduledMintConfig()
+	if err != nil {
+		log.Fatalf("Invalid scheduled mint configuration: %v", err)
+	}
+	if scheduleCfg != nil {
+		go runScheduledMints(scheduleCfg)
+	}
+
+	selfMintCfg, err := loadSelfMintCheckConfig()
+	if err != nil {
+		log.Fatalf("Invalid self-mint check configuration: %v", err)
+	}
+	if selfMintCfg != nil {
+		go runSelfMintCheck(selfMintCfg)
+	}
+
+	if err := loadEventIndexer(); err != nil {
+		log.Fatalf("Invalid event indexer configuration: %v", err)
+	}
+
+	shedder, err = loadLoadShedderConfig()
+	if err != nil {
+		log.Fatalf("Invalid load shedding configuration: %v", err)
+	}
+
+	if err := loadAPIKeys(); err != nil {
+		log.Fatalf("Invalid API key configuration: %v", err)
+	}
+
+	r, err := newRouter()
+	if err != nil {
+		log.Fatalf("Invalid routing configuration: %v", err)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -67,37 +163,101 @@ func main() {
 func initEthereum() error {
 	var err error
 
-	client, err = ethclient.Dial(os.Getenv("ETH_NODE_URL"))
+	if err := loadNetworks(); err != nil {
+		return err
+	}
+
+	// The primary network also serves the endpoints that are not
+	// network-aware (balances, decoding, health, info, alerts).
+	client = primaryNetwork.client
+	fromAddress = primaryNetwork.from
+	contract = primaryNetwork.contract
+	contractAddr = primaryNetwork.contractAddr
+	connectedChainID = primaryNetwork.chainID
+
+	for _, net := range networks {
+		if err := checkAllowedChain(net.chainID, os.Getenv("ALLOWED_CHAIN_IDS")); err != nil {
+			return fmt.Errorf("network %s: %v", net.name, err)
+		}
+		if err := loadSignerConfig(net.chainID); err != nil {
+			return fmt.Errorf("network %s: %v", net.name, err)
+		}
+	}
+
+	if err := loadCooldownConfig(); err != nil {
+		return err
+	}
+
+	if err := loadGasLimits(); err != nil {
+		return err
+	}
+
+	if err := loadFeeConfig(); err != nil {
+		return err
+	}
+
+	if err := loadGasBumpConfig(); err != nil {
+		return err
+	}
+
+	if err := loadGasGuardConfig(); err != nil {
+		return err
+	}
+
+	for _, net := range networks {
+		net.broadcaster, err = loadBroadcaster(net)
+		if err != nil {
+			return err
+		}
+	}
+
+	priceFeed, err = loadFiatPriceFeed()
 	if err != nil {
-		return fmt.Errorf("failed to connect to Ethereum client: %v", err)
+		return err
 	}
 
-	privateKeyHex := os.Getenv("PRIVATE_KEY")
-	if privateKeyHex == "" {
-		return fmt.Errorf("PRIVATE_KEY environment variable is not set")
+	if err := loadBalanceQueryConfig(); err != nil {
+		return err
 	}
 
-	privateKey, err = crypto.HexToECDSA(privateKeyHex)
+	if err := loadConfirmationConfig(); err != nil {
+		return err
+	}
+
+	syncConfirm, err = envBool("SYNC_CONFIRM", false)
 	if err != nil {
-		return fmt.Errorf("invalid private key: %v", err)
+		return err
+	}
+
+	if err := loadTxTrackerConfig(); err != nil {
+		return err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return fmt.Errorf("error casting public key to ECDSA")
+	if err := loadBatchMintConfig(); err != nil {
+		return err
 	}
 
-	fromAddress = crypto.PubkeyToAddress(*publicKeyECDSA)
+	if err := loadMintHistory(); err != nil {
+		return err
+	}
 
-	contractAddr = common.HexToAddress(os.Getenv("CONTRACT_ADDRESS"))
-	if contractAddr == (common.Address{}) {
-		return fmt.Errorf("CONTRACT_ADDRESS environment variable is not set")
+	if err := loadIdempotencyConfig(); err != nil {
+		return err
 	}
 
-	contract, err = NewToken(contractAddr, client)
+	verifyBalanceDelta, err = envBool("VERIFY_BALANCE_DELTA", false)
 	if err != nil {
-		return fmt.Errorf("failed to create contract instance: %v", err)
+		return err
+	}
+
+	signResponses, err = envBool("SIGN_RESPONSES", false)
+	if err != nil {
+		return err
+	}
+
+	tokenDecimals, err = resolveTokenDecimals(readContractDecimals)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -110,103 +270,214 @@ func mintTokensHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Sales <= 0 {
-		respondWithError(w, http.StatusBadRequest, "Sales amount must be positive")
+	amount, err := validateMintRequest(req)
+	if err != nil {
+		respondWithValidationError(w, err)
 		return
 	}
 
-	if !common.IsHexAddress(req.Company) {
-		respondWithError(w, http.StatusBadRequest, "Invalid Ethereum address")
+	if req.DryRun && !featureEnabled(featureDryRun) {
+		respondWithError(w, http.StatusBadRequest, "Dry-run is not enabled on this deployment")
 		return
 	}
-
-	amount := big.NewInt(int64(req.Sales))
-	decimals := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(18), nil)
-	amount.Mul(amount, decimals)
-
-	auth, err := prepareTransaction()
+	net, err := networkFor(req.Network)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to prepare transaction: %v", err))
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-
 	targetAddress := common.HexToAddress(req.Company)
-	tx, err := contract.MintSecure(auth, targetAddress, amount)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to mint tokens: %v", err))
-		return
+
+	wait := syncConfirm
+	if value := r.URL.Query().Get("wait"); value != "" {
+		wait, err = strconv.ParseBool(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "wait must be true or false")
+			return
+		}
 	}
 
-	receipt, err := waitForTransaction(tx.Hash())
+	idemKey, err := idempotencyKeyFor(r, req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Error waiting for transaction: %v", err))
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	if receipt.Status == types.ReceiptStatusFailed {
-		respondWithError(w, http.StatusInternalServerError, "Transaction failed")
+	if req.DryRun {
+		idemKey = ""
+	}
+	if idemKey != "" && !claimIdempotencyKey(w, idemKey, mintFingerprint(net, targetAddress, amount)) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, MintResponse{
-		Success:      true,
-		Message:      "Tokens minted successfully",
-		TxHash:       tx.Hash().Hex(),
-		BlockNumber:  receipt.BlockNumber.Uint64(),
-		AmountMinted: amount.String(),
-	})
-}
+	if !req.DryRun {
+		if message := reserveMint(r.Context(), amount); message != "" {
+			if idemKey != "" {
+				idempotencyKeys.release(idemKey)
+			}
+			respondWithError(w, http.StatusTooManyRequests, message)
+			return
+		}
+	}
 
-func prepareTransaction() (*bind.TransactOpts, error) {
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %v", err)
+	var status int
+	var resp MintResponse
+	if req.DryRun {
+		status, resp = dryRunMint(net, targetAddress, amount)
+	} else if wait {
+		status, resp = mintTo(net, targetAddress, amount)
+	} else {
+		status, resp = mintAsync(net, targetAddress, amount)
+	}
+	resp.Network = net.label()
+	if !req.DryRun {
+		if !resp.mayBeOnChain() {
+			releaseMint(r.Context(), amount)
+		}
+		recordMint("api", net, targetAddress, req.Sales, amount, resp)
+	}
+	if resp.CooldownRemaining > 0 {
+		w.Header().Set("Retry-After", strconv.FormatUint(resp.CooldownRemaining, 10))
 	}
 
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %v", err)
+	resp.CorrelationID = correlationID(r.Context())
+	if !resp.Success {
+		log.Printf("[%s] Mint to %s failed: %s", resp.CorrelationID, targetAddress.Hex(), resp.Message)
+	}
+
+	if signResponses {
+		if err := signMintResponse(net, &resp); err != nil {
+			log.Printf("Failed to sign mint response: %v", err)
+		}
 	}
 
-	chainID, err := client.NetworkID(context.Background())
+	if idemKey != "" {
+		idempotencyKeys.complete(idemKey, status, resp)
+	}
+	respondWithJSON(w, status, resp)
+}
+
+// prepareTransaction builds transact options with fees and the configured gas
+// limit. It does not reserve a nonce; callers take one from the network's
+// nonce manager once their pre-flight checks have passed.
+func prepareTransaction(net *network, method string) (*bind.TransactOpts, error) {
+	quote, err := suggestDynamicFees(context.Background(), net)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get chain ID: %v", err)
+		return nil, err
+	}
+
+	var gasPrice *big.Int
+	if quote == nil {
+		gasPrice, err = net.client.SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %v", err)
+		}
+		gasPrice = capGasPrice(gasPrice)
 	}
 
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	auth, err := newTransactor(net)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transactor: %v", err)
 	}
 
-	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = big.NewInt(0)
-	auth.GasLimit = uint64(300000)
-	auth.GasPrice = gasPrice
+	auth.GasLimit = gasLimitFor(method)
+	if quote != nil {
+		auth.GasTipCap = quote.tipCap
+		auth.GasFeeCap = quote.feeCap
+	} else {
+		auth.GasPrice = gasPrice
+	}
 
 	return auth, nil
 }
 
-func waitForTransaction(txHash common.Hash) (*types.Receipt, error) {
-	ctx := context.Background()
-	timeout := time.After(5 * time.Minute)
+func waitForTransaction(net *network, txHash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	receipt, err := waitForReceipt(ctx, net, txHash)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timeout waiting for transaction")
+	}
+	return receipt, err
+}
+
+// waitForReceipt polls for the receipt of txHash until it has the required
+// confirmations or ctx is done.
+func waitForReceipt(ctx context.Context, net *network, txHash common.Hash) (*types.Receipt, error) {
+	return newReceiptWatch(net).wait(ctx, []common.Hash{txHash})
+}
+
+// receiptWatch remembers the receipts seen for one mint, which may have been
+// sent as several versions sharing a nonce, so a reorg that moves or drops
+// any of them is noticed across polls.
+type receiptWatch struct {
+	net  *network
+	seen map[common.Hash]*types.Receipt
+}
+
+func newReceiptWatch(net *network) *receiptWatch {
+	return &receiptWatch{net: net, seen: make(map[common.Hash]*types.Receipt)}
+}
+
+// wait polls for a receipt of any of hashes until one has the required
+// confirmations or ctx is done. The first poll happens straight away; a mint
+// that is already mined, such as one picked up late from the confirm queue,
+// returns at once.
+func (w *receiptWatch) wait(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	for {
+		receipt, err := w.check(ctx, hashes)
+		if receipt != nil || err != nil {
+			return receipt, err
+		}
+
 		select {
-		case <-timeout:
-			return nil, fmt.Errorf("timeout waiting for transaction")
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		case <-ticker.C:
-			receipt, err := client.TransactionReceipt(ctx, txHash)
+		}
+	}
+}
+
+// check looks every hash up once and returns the first receipt that has the
+// required confirmations, or nil when none is final yet.
+func (w *receiptWatch) check(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
+	var head *uint64
+	for _, hash := range hashes {
+		receipt, err := w.net.client.TransactionReceipt(ctx, hash)
+		if err != nil {
+			if err.Error() != "not found" {
+				return nil, err
+			}
+			if seen := w.seen[hash]; seen != nil {
+				observeReorg(w.net, hash, seen, nil)
+				delete(w.seen, hash)
+			}
+			continue
+		}
+
+		if seen := w.seen[hash]; seen != nil && seen.BlockHash != receipt.BlockHash {
+			observeReorg(w.net, hash, seen, receipt)
+		}
+		w.seen[hash] = receipt
+
+		if requiredConfirmations <= 1 {
+			return receipt, nil
+		}
+		if head == nil {
+			number, err := w.net.client.BlockNumber(ctx)
 			if err != nil {
-				if err.Error() == "not found" {
-					continue
-				}
 				return nil, err
 			}
+			head = &number
+		}
+		if *head+1 >= receipt.BlockNumber.Uint64()+requiredConfirmations {
 			return receipt, nil
 		}
 	}
+	return nil, nil
 }
 
 func respondWithError(w http.ResponseWriter, code int, message string) {
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// mintTo submits a mint and waits for it to be mined, returning the HTTP
+// status and response body describing the outcome.
+func mintTo(net *network, targetAddress common.Address, amount *big.Int) (int, MintResponse) {
+	stats.inFlightMints.Add(1)
+	defer stats.inFlightMints.Add(-1)
+
+	status, resp := submitMint(net, targetAddress, amount)
+	stats.recordOutcome(resp.Success)
+	return status, resp
+}
+
+// mintAsync broadcasts a mint and returns immediately with a pending status
+// and job ID. A confirmation worker waits for the receipt and records the
+// final outcome in the tx tracker, where GET /mint/{jobId} and
+// GET /status/{hash} can find it.
+func mintAsync(net *network, targetAddress common.Address, amount *big.Int) (int, MintResponse) {
+	stats.inFlightMints.Add(1)
+	tx, status, resp := broadcastMint(net, targetAddress, amount)
+	stats.inFlightMints.Add(-1)
+	if tx == nil {
+		stats.recordOutcome(false)
+		return status, resp
+	}
+
+	jobID := trackedTxs.add(net, tx, targetAddress, amount.String())
+	enqueueConfirm(confirmJob{net: net, tx: tx, target: targetAddress, amount: amount})
+
+	nonce := tx.Nonce()
+	return http.StatusAccepted, MintResponse{
+		Success:      true,
+		Message:      "Transaction broadcast; poll /mint/{jobId} for confirmation",
+		Status:       txStatusPending,
+		JobID:        jobID,
+		TxHash:       tx.Hash().Hex(),
+		Nonce:        &nonce,
+		AmountMinted: amount.String(),
+	}
+}
+
+func submitMint(net *network, targetAddress common.Address, amount *big.Int) (int, MintResponse) {
+	tx, status, resp := broadcastMint(net, targetAddress, amount)
+	if tx == nil {
+		return status, resp
+	}
+	return confirmMint(net, tx, targetAddress, amount)
+}
+
+// broadcastMint runs the pre-flight checks, signs the mint and hands it to
+// the broadcaster. It returns a nil transaction with the failure response
+// when the mint could not be sent.
+func broadcastMint(net *network, targetAddress common.Address, amount *big.Int) (*types.Transaction, int, MintResponse) {
+	cooldown, err := remainingCooldown(net, targetAddress)
+	if err != nil {
+		return nil, http.StatusInternalServerError, MintResponse{Message: err.Error()}
+	}
+	if cooldown.Sign() > 0 {
+		return nil, http.StatusTooManyRequests, MintResponse{
+			Message:           fmt.Sprintf("Recipient is in an on-chain mint cooldown for another %s seconds", cooldown),
+			CooldownRemaining: cooldown.Uint64(),
+		}
+	}
+
+	// A nonce already taken on the node means something else sent from this
+	// account; the nonce manager resyncs and the mint is signed again once.
+	for attempt := 1; ; attempt++ {
+		auth, err := prepareTransaction(net, "mint_secure")
+		if err != nil {
+			return nil, http.StatusInternalServerError, MintResponse{Message: fmt.Sprintf("Failed to prepare transaction: %v", err)}
+		}
+
+		// Replace the configured gas limit with a padded estimate when the
+		// node can give one; a revert here would only be paid for on-chain.
+		if gas, err := simulateMint(net, targetAddress, amount); err == nil {
+			auth.GasLimit = scaleGasLimit(gas)
+		} else if isRevertError(err) {
+			return nil, http.StatusInternalServerError, MintResponse{
+				Message: withRevertReason("Failed to mint tokens: execution reverted", decodeRevertReason(err)),
+			}
+		} else {
+			log.Printf("Gas estimation for mint to %s failed, using limit %d: %v", targetAddress.Hex(), auth.GasLimit, err)
+		}
+
+		cost, balance, ok, err := checkGasHeadroom(net, auth)
+		if err != nil {
+			return nil, http.StatusInternalServerError, MintResponse{Message: fmt.Sprintf("Failed to check gas headroom: %v", err)}
+		}
+		if !ok {
+			return nil, http.StatusServiceUnavailable, MintResponse{
+				Message: fmt.Sprintf("Projected gas cost %s ETH exceeds %.0f%% of deployer balance %s ETH",
+					formatEther(cost), maxGasBalanceFraction*100, formatEther(balance)),
+				ProjectedGasCost: cost.String(),
+				DeployerBalance:  balance.String(),
+			}
+		}
+
+		// The nonce is reserved only now, so the RPCs above do not hold it
+		// while other mints are waiting behind it.
+		nonce, err := net.nonces.Next(context.Background())
+		if err != nil {
+			return nil, http.StatusInternalServerError, MintResponse{Message: fmt.Sprintf("Failed to prepare transaction: %v", err)}
+		}
+		auth.Nonce = new(big.Int).SetUint64(nonce)
+
+		auth.NoSend = true
+		tx, err := net.contract.MintSecure(auth, targetAddress, amount)
+		if err != nil {
+			net.nonces.Release(nonce)
+			return nil, http.StatusInternalServerError, MintResponse{Message: mintFailureMessage(err)}
+		}
+
+		// Once the transaction is signed, only a node that clearly refused it
+		// proves it is not on its way. Anything else, such as a timeout, may
+		// have reached the mempool: the mint is then confirmed like any other,
+		// so its nonce, cap reservation and idempotency key are all kept.
+		err = net.broadcaster.Broadcast(context.Background(), tx)
+		if err == nil {
+			return tx, http.StatusAccepted, MintResponse{}
+		}
+		if !isBroadcastRejection(err) {
+			log.Printf("Broadcast of mint %s (nonce %d) to %s failed; treating it as possibly sent: %v",
+				tx.Hash().Hex(), nonce, net.name, err)
+			return tx, http.StatusAccepted, MintResponse{}
+		}
+
+		releaseNonce(net, nonce, err)
+		if isStaleNonceError(err) && attempt < 2 {
+			continue
+		}
+		return nil, http.StatusInternalServerError, MintResponse{
+			Message:  mintFailureMessage(err),
+			TxHash:   tx.Hash().Hex(),
+			Nonce:    &nonce,
+			rejected: true,
+		}
+	}
+}
+
+func mintFailureMessage(err error) string {
+	if reason := decodeRevertReason(err); reason != "" {
+		return fmt.Sprintf("Failed to mint tokens: execution reverted: %s", reason)
+	}
+	return fmt.Sprintf("Failed to mint tokens: %v", err)
+}
+
+// confirmMint waits for a broadcast mint to be mined and describes the
+// outcome. A mint whose receipt was not seen in time is reported with the
+// unknown status rather than as failed: it is still on its way, so callers
+// keep its cap reservation and idempotency key.
+func confirmMint(net *network, tx *types.Transaction, targetAddress common.Address, amount *big.Int) (int, MintResponse) {
+	nonce := tx.Nonce()
+
+	stats.pendingTxs.Add(1)
+	receipt, err := net.broadcaster.WaitMined(tx)
+	stats.pendingTxs.Add(-1)
+	if err != nil {
+		return http.StatusGatewayTimeout, MintResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error waiting for transaction: %v; it may still be mined, check /status/%s", err, tx.Hash().Hex()),
+			Status:  txStatusUnknown,
+			TxHash:  tx.Hash().Hex(),
+			Nonce:   &nonce,
+		}
+	}
+
+	// A gas-bumped replacement has its own hash; report the one that was
+	// actually mined.
+	var originalTxHash string
+	if receipt.TxHash != tx.Hash() {
+		originalTxHash = tx.Hash().Hex()
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		return http.StatusInternalServerError, MintResponse{
+			Success:        false,
+			Message:        withRevertReason("Transaction failed", replayRevertReason(net, tx, receipt)),
+			TxHash:         receipt.TxHash.Hex(),
+			OriginalTxHash: originalTxHash,
+			Nonce:          &nonce,
+		}
+	}
+
+	resp := MintResponse{
+		Success:        true,
+		Message:        "Tokens minted successfully",
+		TxHash:         receipt.TxHash.Hex(),
+		OriginalTxHash: originalTxHash,
+		Nonce:          &nonce,
+		BlockNumber:    receipt.BlockNumber.Uint64(),
+		AmountMinted:   amount.String(),
+	}
+
+	if receipt.EffectiveGasPrice != nil {
+		gasCost := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		resp.GasUsed = receipt.GasUsed
+		resp.GasCost = gasCost.String()
+		if priceFeed != nil {
+			if fiat, ok := priceFeed.convert(gasCost); ok {
+				resp.GasCostFiat = &fiat
+				resp.FiatCurrency = priceFeed.currency
+			}
+		}
+	}
+
+	if credited, ok := creditedAmount(net, receipt, targetAddress); ok {
+		resp.AmountCredited = credited.String()
+		if credited.Cmp(amount) != 0 {
+			resp.AmountMismatch = true
+			resp.AmountDifference = new(big.Int).Sub(amount, credited).String()
+			log.Printf("Mint %s credited %s to %s but %s was requested",
+				tx.Hash().Hex(), credited, targetAddress.Hex(), amount)
+		}
+	}
+
+	if verifyBalanceDelta {
+		delta, err := observedBalanceDelta(net, targetAddress, receipt.BlockNumber)
+		if err != nil {
+			log.Printf("Mint %s: failed to verify balance delta: %v", tx.Hash().Hex(), err)
+		} else {
+			resp.BalanceDelta = delta.String()
+			if delta.Cmp(amount) != 0 {
+				resp.BalanceDeltaMismatch = true
+				log.Printf("Mint %s: balance of %s changed by %s, expected %s",
+					tx.Hash().Hex(), targetAddress.Hex(), delta, amount)
+			}
+		}
+	}
+
+	return http.StatusOK, resp
+}
+
+func dryRunMint(net *network, to common.Address, amount *big.Int) (int, MintResponse) {
+	gas, err := simulateMint(net, to, amount)
+	if err != nil {
+		if !isRevertError(err) {
+			return http.StatusInternalServerError, MintResponse{Message: fmt.Sprintf("Failed to simulate mint: %v", err)}
+		}
+		return http.StatusOK, MintResponse{
+			Success:   false,
+			Message:   withRevertReason("Simulated mint would revert", decodeRevertReason(err)),
+			Simulated: true,
+		}
+	}
+
+	return http.StatusOK, MintResponse{
+		Success:      true,
+		Message:      "Simulated mint would succeed; no transaction was broadcast",
+		AmountMinted: amount.String(),
+		Simulated:    true,
+		EstimatedGas: gas,
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestConfirmMintFlagsReducedAmount(t *testing.T) {
+	net := newTestNetwork(t)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 3, To: &testContractAddr, Gas: 100000, GasPrice: big.NewInt(1)})
+	net.broadcaster = &stubBroadcaster{receipt: func(tx *types.Transaction) (*types.Receipt, error) {
+		return &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			TxHash:      tx.Hash(),
+			BlockNumber: big.NewInt(10),
+			Logs:        []*types.Log{transferLog(t, testContractAddr, common.Address{}, testRecipient, 90)},
+		}, nil
+	}}
+
+	status, resp := confirmMint(net, tx, testRecipient, big.NewInt(100))
+	if status != 200 || !resp.Success {
+		t.Fatalf("confirmMint() = %d, %+v", status, resp)
+	}
+	if resp.AmountMinted != "100" || resp.AmountCredited != "90" {
+		t.Errorf("amountMinted = %s, amountCredited = %s; want 100, 90", resp.AmountMinted, resp.AmountCredited)
+	}
+	if !resp.AmountMismatch || resp.AmountDifference != "10" {
+		t.Errorf("amountMismatch = %v, amountDifference = %s; want true, 10", resp.AmountMismatch, resp.AmountDifference)
+	}
+}
+
+func TestConfirmMintFullAmount(t *testing.T) {
+	net := newTestNetwork(t)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 3, To: &testContractAddr, Gas: 100000, GasPrice: big.NewInt(1)})
+	net.broadcaster = &stubBroadcaster{receipt: func(tx *types.Transaction) (*types.Receipt, error) {
+		return &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			TxHash:      tx.Hash(),
+			BlockNumber: big.NewInt(10),
+			Logs:        []*types.Log{transferLog(t, testContractAddr, common.Address{}, testRecipient, 100)},
+		}, nil
+	}}
+
+	_, resp := confirmMint(net, tx, testRecipient, big.NewInt(100))
+	if resp.AmountMismatch || resp.AmountCredited != "100" {
+		t.Errorf("amountMismatch = %v, amountCredited = %s; want false, 100", resp.AmountMismatch, resp.AmountCredited)
+	}
+}
+
+func TestMintBroadcastFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		broadcastErr  string
+		wantHeld      bool // key, cap reservation and nonce kept
+		wantSecondTxs int  // broadcasts after retrying with the same key
+	}{
+		{name: "timeout may have reached the node", broadcastErr: "Post \"http://node\": context deadline exceeded", wantHeld: true, wantSecondTxs: 1},
+		{name: "already known", broadcastErr: "already known", wantHeld: true, wantSecondTxs: 1},
+		{name: "insufficient funds", broadcastErr: "insufficient funds for gas * price + value", wantSecondTxs: 2},
+		{name: "intrinsic gas too low", broadcastErr: "intrinsic gas too low", wantSecondTxs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, broadcaster := newMintTestNetwork(t, mintNodeHandlers(), 7)
+			setGlobal(t, &idempotencyKeys, &idempotencyStore{retention: time.Hour, entries: make(map[string]*idempotencyEntry)})
+
+			// Only the first broadcast fails.
+			broadcaster.broadcast = func(*types.Transaction) error {
+				if len(broadcaster.sent()) == 1 {
+					return errors.New(tt.broadcastErr)
+				}
+				return nil
+			}
+			caller := &apiKey{name: "client", maxPerDay: big.NewInt(1e18), usedDay: new(big.Int)}
+
+			send := func() MintResponse {
+				body := `{"company":"` + testRecipient.Hex() + `","sales":"1"}`
+				req := httptest.NewRequest(http.MethodPost, "/mint?wait=true", strings.NewReader(body))
+				req.Header.Set(idempotencyKeyHeader, "order-1")
+				req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, caller))
+				w := httptest.NewRecorder()
+				mintTokensHandler(w, req)
+				var resp MintResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				return resp
+			}
+
+			first := send()
+			sent := broadcaster.sent()
+			if len(sent) != 1 || first.TxHash != sent[0].Hash().Hex() {
+				t.Fatalf("response txHash = %q, want the signed transaction's hash", first.TxHash)
+			}
+			if first.Success != tt.wantHeld {
+				t.Errorf("success = %v, want %v (%s)", first.Success, tt.wantHeld, first.Message)
+			}
+			wantUsed := int64(0)
+			if tt.wantHeld {
+				wantUsed = 1e18
+			}
+			if caller.usedDay.Int64() != wantUsed {
+				t.Errorf("daily cap used = %s, want %d", caller.usedDay, wantUsed)
+			}
+
+			send()
+			sent = broadcaster.sent()
+			if len(sent) != tt.wantSecondTxs {
+				t.Fatalf("retry with the same key broadcast %d transactions in total, want %d", len(sent), tt.wantSecondTxs)
+			}
+			if tt.wantSecondTxs == 2 && sent[1].Nonce() != sent[0].Nonce() {
+				t.Errorf("retry used nonce %d, want the released nonce %d", sent[1].Nonce(), sent[0].Nonce())
+			}
+
+			// Either the first mint still holds nonce 7 or the retry took it.
+			if next, _ := net.nonces.Next(context.Background()); next != 8 {
+				t.Errorf("next nonce = %d, want 8", next)
+			}
+		})
+	}
+}
+
+func TestMintConfirmationTimeout(t *testing.T) {
+	_, broadcaster := newMintTestNetwork(t, mintNodeHandlers(), 7)
+	setGlobal(t, &idempotencyKeys, &idempotencyStore{retention: time.Hour, entries: make(map[string]*idempotencyEntry)})
+	broadcaster.receipt = func(*types.Transaction) (*types.Receipt, error) {
+		return nil, errors.New("timeout waiting for transaction")
+	}
+	caller := &apiKey{name: "client", maxPerDay: big.NewInt(1e18), usedDay: new(big.Int)}
+
+	send := func() (int, MintResponse) {
+		body := `{"company":"` + testRecipient.Hex() + `","sales":"1"}`
+		req := httptest.NewRequest(http.MethodPost, "/mint?wait=true", strings.NewReader(body))
+		req.Header.Set(idempotencyKeyHeader, "order-1")
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, caller))
+		w := httptest.NewRecorder()
+		mintTokensHandler(w, req)
+		var resp MintResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	status, first := send()
+	if status != http.StatusGatewayTimeout || first.Status != txStatusUnknown || first.TxHash == "" {
+		t.Fatalf("mint = %d, status %q, txHash %q; want %d, %q and the hash", status, first.Status, first.TxHash, http.StatusGatewayTimeout, txStatusUnknown)
+	}
+	if caller.usedDay.Int64() != 1e18 {
+		t.Errorf("daily cap used = %s, want the reservation kept", caller.usedDay)
+	}
+	if _, second := send(); second.TxHash != first.TxHash || len(broadcaster.sent()) != 1 {
+		t.Errorf("retry with the same key broadcast %d transactions, want the first mint replayed", len(broadcaster.sent()))
+	}
+}
+
+func TestAsyncMintConfirmationTimeout(t *testing.T) {
+	net, broadcaster := newMintTestNetwork(t, mintNodeHandlers(), 7)
+	broadcaster.receipt = func(*types.Transaction) (*types.Receipt, error) {
+		return nil, errors.New("timeout waiting for transaction")
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &testContractAddr, Gas: 100000, GasPrice: big.NewInt(1)})
+	jobID := trackedTxs.add(net, tx, testRecipient, "100")
+
+	runConfirmJob(confirmJob{net: net, tx: tx, target: testRecipient, amount: big.NewInt(100)})
+
+	entry, _ := trackedTxs.getJob(jobID)
+	if entry.Status != txStatusUnknown {
+		t.Errorf("timed-out job status = %q, want %q", entry.Status, txStatusUnknown)
+	}
+}
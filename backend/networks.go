@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// network is one chain the service mints on: its node connection, token
+// binding, signer and nonce manager. Everything else (fee strategy, gas
+// limits, cooldowns, feature flags) is shared across networks.
+type network struct {
+	name         string
+	client       *ethclient.Client
+	chainID      *big.Int
+	contract     *Token
+	contractAddr common.Address
+	signer       Signer
+	from         common.Address
+	nonces       *NonceManager
+	broadcaster  Broadcaster
+}
+
+// networkConfig is one entry of NETWORKS_FILE. Signer settings are read from
+// the usual variables with SignerEnvPrefix prepended (for example
+// POLYGON_SIGNER_TYPE, POLYGON_PRIVATE_KEY), so secrets stay out of the file.
+type networkConfig struct {
+	Name            string `json:"name"`
+	RPCURL          string `json:"rpcUrl"`
+	ChainID         uint64 `json:"chainId,omitempty"`
+	ContractAddress string `json:"contractAddress"`
+	SignerEnvPrefix string `json:"signerEnvPrefix,omitempty"`
+}
+
+type networksFile struct {
+	Primary  string          `json:"primary"`
+	Networks []networkConfig `json:"networks"`
+}
+
+const defaultNetworkName = "default"
+
+var (
+	networks       map[string]*network
+	primaryNetwork *network
+)
+
+// loadNetworks connects to every configured network. Without NETWORKS_FILE a
+// single network is built from ETH_NODE_URL, CONTRACT_ADDRESS and the
+// unprefixed signer variables.
+func loadNetworks() error {
+	file := networksFile{
+		Primary: defaultNetworkName,
+		Networks: []networkConfig{{
+			Name:            defaultNetworkName,
+			RPCURL:          os.Getenv("ETH_NODE_URL"),
+			ContractAddress: os.Getenv("CONTRACT_ADDRESS"),
+		}},
+	}
+
+	if path := os.Getenv("NETWORKS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read networks file: %v", err)
+		}
+		file = networksFile{}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse networks file: %v", err)
+		}
+		if len(file.Networks) == 0 {
+			return fmt.Errorf("networks file %s has no networks", path)
+		}
+		if file.Primary == "" {
+			file.Primary = file.Networks[0].Name
+		}
+	}
+
+	networks = make(map[string]*network, len(file.Networks))
+	for _, cfg := range file.Networks {
+		if cfg.Name == "" {
+			return fmt.Errorf("every network needs a name")
+		}
+		if _, dup := networks[cfg.Name]; dup {
+			return fmt.Errorf("network %q is configured twice", cfg.Name)
+		}
+		net, err := connectNetwork(cfg)
+		if err != nil {
+			return fmt.Errorf("network %s: %v", cfg.Name, err)
+		}
+		networks[cfg.Name] = net
+	}
+
+	primaryNetwork = networks[file.Primary]
+	if primaryNetwork == nil {
+		return fmt.Errorf("primary network %q is not configured", file.Primary)
+	}
+	return nil
+}
+
+func connectNetwork(cfg networkConfig) (*network, error) {
+	var err error
+	net := &network{name: cfg.Name}
+
+	net.client, err = ethclient.Dial(cfg.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %v", err)
+	}
+
+	net.signer, err = loadSigner(cfg.SignerEnvPrefix)
+	if err != nil {
+		return nil, err
+	}
+	net.from = net.signer.Address()
+
+	if !common.IsHexAddress(cfg.ContractAddress) {
+		return nil, fmt.Errorf("contract address is not set")
+	}
+	net.contractAddr = common.HexToAddress(cfg.ContractAddress)
+	net.contract, err = NewToken(net.contractAddr, net.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract instance: %v", err)
+	}
+
+	net.chainID, err = net.client.NetworkID(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %v", err)
+	}
+	if cfg.ChainID != 0 && (!net.chainID.IsUint64() || net.chainID.Uint64() != cfg.ChainID) {
+		return nil, fmt.Errorf("node reports chain ID %s, configured %d", net.chainID, cfg.ChainID)
+	}
+
+	net.nonces = NewNonceManager(net.client, net.from)
+	if err := net.nonces.Sync(context.Background()); err != nil {
+		return nil, err
+	}
+	return net, nil
+}
+
+// networkFor resolves the optional "network" field of a request.
+func networkFor(name string) (*network, error) {
+	if name == "" {
+		return primaryNetwork, nil
+	}
+	net, ok := networks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q (configured: %s)", name, strings.Join(networkNames(), ", "))
+	}
+	return net, nil
+}
+
+func networkNames() []string {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// label is the name reported in responses, left empty when only one
+// network is configured so single-network deployments see no change.
+func (n *network) label() string {
+	if len(networks) <= 1 {
+		return ""
+	}
+	return n.name
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type pendingNonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out sequential nonces for a single sending account so
+// concurrent mints never reuse a nonce. It syncs from the node's pending
+// nonce on first use and again when the node rejects a nonce. Nonces given
+// back without being broadcast go on a free list and are handed out again
+// before new ones, so a gap is refilled without asking the node, which
+// cannot see nonces that are reserved but not yet sent. For the same reason
+// a resync only ever moves the counter forward.
+type NonceManager struct {
+	mu       sync.Mutex
+	source   pendingNonceSource
+	account  common.Address
+	next     uint64
+	released []uint64 // sorted, all below next
+	synced   bool
+}
+
+func NewNonceManager(source pendingNonceSource, account common.Address) *NonceManager {
+	return &NonceManager{source: source, account: account}
+}
+
+// Sync reloads the next nonce from the node's pending state. A pending
+// nonce behind the counter is ignored: the nonces in between may be reserved
+// by mints that have not been broadcast yet.
+func (m *NonceManager) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.syncLocked(ctx)
+}
+
+func (m *NonceManager) syncLocked(ctx context.Context) error {
+	nonce, err := m.source.PendingNonceAt(ctx, m.account)
+	if err != nil {
+		m.synced = false
+		return fmt.Errorf("failed to get nonce: %v", err)
+	}
+	if nonce > m.next {
+		m.next = nonce
+	}
+	// Released nonces the node has already seen were used by another sender.
+	i, _ := slices.BinarySearch(m.released, nonce)
+	m.released = m.released[i:]
+	m.synced = true
+	return nil
+}
+
+// Next reserves and returns the lowest released nonce, or the next new one.
+func (m *NonceManager) Next(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced {
+		if err := m.syncLocked(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if len(m.released) > 0 {
+		nonce := m.released[0]
+		m.released = m.released[1:]
+		return nonce, nil
+	}
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// Release returns a reserved nonce that was never broadcast. The most recent
+// reservation simply steps the counter back; an earlier one goes on the free
+// list for the next reservation, since later nonces are still in use.
+func (m *NonceManager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if nonce >= m.next {
+		return
+	}
+	i, found := slices.BinarySearch(m.released, nonce)
+	if found {
+		return
+	}
+	if nonce+1 != m.next {
+		m.released = slices.Insert(m.released, i, nonce)
+		return
+	}
+
+	m.next--
+	for len(m.released) > 0 && m.released[len(m.released)-1]+1 == m.next {
+		m.released = m.released[:len(m.released)-1]
+		m.next--
+	}
+}
+
+// Invalidate forces a resync from the node on the next reservation.
+// Outstanding reservations stay valid and can still be released.
+func (m *NonceManager) Invalidate() {
+	m.mu.Lock()
+	m.synced = false
+	m.mu.Unlock()
+}
+
+func isNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "nonce too high") ||
+		strings.Contains(msg, "invalid nonce") ||
+		strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// isStaleNonceError reports whether the node rejected the transaction
+// because its nonce was already taken, in which case signing the mint again
+// with a resynced nonce is safe. "already known" is deliberately excluded:
+// it means this very transaction is already in the pool.
+func isStaleNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// releaseNonce gives back a nonce after a failed send, also resyncing when
+// the node rejected the nonce itself. The resync drops the nonce again if the
+// node reports it as used.
+func releaseNonce(net *network, nonce uint64, err error) {
+	net.nonces.Release(nonce)
+	if isNonceError(err) {
+		log.Printf("Nonce %d rejected by %s node (%v); resyncing nonce manager", nonce, net.name, err)
+		net.nonces.Invalidate()
+	}
+}
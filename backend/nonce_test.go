@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNonceManagerConcurrentNext(t *testing.T) {
+	source := &fixedNonceSource{nonce: 7}
+	m := NewNonceManager(source, testRecipient)
+
+	const workers = 50
+	nonces := make([]uint64, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce, err := m.Next(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			nonces[i] = nonce
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(7+i) {
+			t.Fatalf("nonces = %v, want 7..%d each exactly once", nonces, 7+workers-1)
+		}
+	}
+	if source.calls != 1 {
+		t.Errorf("PendingNonceAt called %d times, want 1", source.calls)
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	source := &fixedNonceSource{nonce: 10}
+	m := NewNonceManager(source, testRecipient)
+	next := func() uint64 {
+		t.Helper()
+		nonce, err := m.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce
+	}
+
+	a, b, c := next(), next(), next() // 10, 11, 12
+
+	// Releasing the newest reservation steps the counter back.
+	m.Release(c)
+	if got := next(); got != c {
+		t.Fatalf("after releasing the newest nonce, Next() = %d, want %d", got, c)
+	}
+
+	// Releasing an earlier one while later nonces are in flight must not
+	// resync: the node would report 10 and hand out nonces still in use.
+	m.Release(a)
+	if got := next(); got != a {
+		t.Fatalf("after releasing %d, Next() = %d, want %d", a, got, a)
+	}
+	if got := next(); got != 13 {
+		t.Fatalf("Next() = %d, want 13", got)
+	}
+	if source.calls != 1 {
+		t.Errorf("PendingNonceAt called %d times, want 1", source.calls)
+	}
+
+	// Released nonces just below the counter collapse into it.
+	m.Release(b)
+	m.Release(13)
+	m.Release(c)
+	if got := next(); got != b {
+		t.Fatalf("Next() = %d, want %d", got, b)
+	}
+	if got := next(); got != c {
+		t.Fatalf("Next() = %d, want %d", got, c)
+	}
+
+	// A nonce error forces a resync, which drops the free list.
+	m.Release(b)
+	m.Invalidate()
+	source.nonce = 20
+	if got := next(); got != 20 {
+		t.Fatalf("after Invalidate, Next() = %d, want 20", got)
+	}
+}
+
+func TestNonceManagerResyncKeepsReservations(t *testing.T) {
+	// The node only sees broadcast transactions, so its pending nonce stays
+	// at 7 however many nonces are reserved.
+	source := &fixedNonceSource{nonce: 7}
+	m := NewNonceManager(source, testRecipient)
+
+	const workers = 50
+	nonces := make([]uint64, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				m.Invalidate()
+			}
+			nonce, err := m.Next(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			nonces[i] = nonce
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(7+i) {
+			t.Fatalf("nonces = %v, want 7..%d each exactly once despite resyncs", nonces, 7+workers-1)
+		}
+	}
+
+	// Nonces released around a resync are reused unless the node has
+	// since seen them used.
+	m.Release(8)
+	m.Invalidate()
+	m.Release(30)
+	source.mu.Lock()
+	source.nonce = 20
+	source.mu.Unlock()
+	for _, want := range []uint64{30, 57} {
+		if got, err := m.Next(context.Background()); err != nil || got != want {
+			t.Errorf("Next() = %d, %v; want %d", got, err, want)
+		}
+	}
+}
+
+func TestParallelMintRequestsUseSequentialNonces(t *testing.T) {
+	_, broadcaster := newMintTestNetwork(t, mintNodeHandlers(), 42)
+
+	const requests = 20
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"company":"0x%040x","sales":"1.5"}`, i+1)
+			req := httptest.NewRequest(http.MethodPost, "/mint?wait=true", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			mintTokensHandler(w, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("request %d: status %d: %s", i, w.Code, w.Body.String())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sent := broadcaster.sent()
+	if len(sent) != requests {
+		t.Fatalf("broadcast %d transactions, want %d", len(sent), requests)
+	}
+	nonces := make([]uint64, len(sent))
+	for i, tx := range sent {
+		nonces[i] = tx.Nonce()
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(42+i) {
+			t.Fatalf("broadcast nonces = %v, want 42..%d each exactly once", nonces, 42+requests-1)
+		}
+	}
+}
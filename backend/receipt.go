@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// creditedAmount sums the mint Transfer events (from the zero address to the
+// recipient) emitted by the token contract in a receipt. Other Transfer logs
+// in the same receipt, such as fee transfers, are ignored so the result
+// reflects what the recipient actually received from the mint.
+func creditedAmount(net *network, receipt *types.Receipt, to common.Address) (*big.Int, bool) {
+	total := new(big.Int)
+	found := false
+
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != net.contractAddr {
+			continue
+		}
+		transfer, err := net.contract.ParseTransfer(*vLog)
+		if err != nil || transfer.From != (common.Address{}) || transfer.To != to {
+			continue
+		}
+		total.Add(total, transfer.Value)
+		found = true
+	}
+
+	return total, found
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCreditedAmount(t *testing.T) {
+	net := newTestNetwork(t)
+	zero := common.Address{}
+	feeCollector := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	otherToken := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	tests := []struct {
+		name      string
+		logs      []*types.Log
+		want      int64
+		wantFound bool
+	}{
+		{
+			name: "single mint",
+			logs: []*types.Log{transferLog(t, testContractAddr, zero, testRecipient, 100)},
+			want: 100, wantFound: true,
+		},
+		{
+			name: "fee transfer out of recipient is ignored",
+			logs: []*types.Log{
+				transferLog(t, testContractAddr, zero, testRecipient, 100),
+				transferLog(t, testContractAddr, testRecipient, feeCollector, 5),
+			},
+			want: 100, wantFound: true,
+		},
+		{
+			name: "mint to another address is ignored",
+			logs: []*types.Log{
+				transferLog(t, testContractAddr, zero, feeCollector, 7),
+				transferLog(t, testContractAddr, zero, testRecipient, 93),
+			},
+			want: 93, wantFound: true,
+		},
+		{
+			name: "several mints to recipient are summed",
+			logs: []*types.Log{
+				transferLog(t, testContractAddr, zero, testRecipient, 60),
+				transferLog(t, testContractAddr, zero, testRecipient, 40),
+			},
+			want: 100, wantFound: true,
+		},
+		{
+			name: "logs from another contract are ignored",
+			logs: []*types.Log{transferLog(t, otherToken, zero, testRecipient, 100)},
+			want: 0, wantFound: false,
+		},
+		{
+			name: "no logs",
+			want: 0, wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := creditedAmount(net, &types.Receipt{Logs: tt.logs}, testRecipient)
+			if found != tt.wantFound || got.Cmp(big.NewInt(tt.want)) != 0 {
+				t.Errorf("creditedAmount() = %s, %v; want %d, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
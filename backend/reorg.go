@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// requiredConfirmations is the number of blocks (including the one holding
+// the receipt) to wait for before a mint is considered final. Reorgs can
+// only be observed while waiting, so values of 0 or 1 disable detection.
+var requiredConfirmations uint64
+
+var maxReorgDepth atomic.Uint64
+
+func loadConfirmationConfig() error {
+	confirmations, err := envInt("CONFIRMATIONS", 1)
+	if err != nil {
+		return err
+	}
+	if confirmations < 0 {
+		confirmations = 0
+	}
+	requiredConfirmations = uint64(confirmations)
+	return nil
+}
+
+// observeReorg records a change in the block a receipt was included in. The
+// depth is the number of blocks from the previously seen inclusion block up
+// to the current head, i.e. how far back the chain was rewritten.
+func observeReorg(net *network, txHash common.Hash, previous, current *types.Receipt) {
+	depth := uint64(1)
+	if head, err := net.client.BlockNumber(context.Background()); err == nil && head >= previous.BlockNumber.Uint64() {
+		depth = head - previous.BlockNumber.Uint64() + 1
+	}
+
+	for {
+		old := maxReorgDepth.Load()
+		if depth <= old || maxReorgDepth.CompareAndSwap(old, depth) {
+			break
+		}
+	}
+
+	if current == nil {
+		log.Printf("WARN: reorg of depth %d removed tx %s from block %d (%s)",
+			depth, txHash.Hex(), previous.BlockNumber, previous.BlockHash.Hex())
+		return
+	}
+	log.Printf("WARN: reorg of depth %d moved tx %s from block %d (%s) to block %d (%s)",
+		depth, txHash.Hex(), previous.BlockNumber, previous.BlockHash.Hex(), current.BlockNumber, current.BlockHash.Hex())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var signResponses bool
+
+// canonicalMintResponse returns the bytes covered by a mint response
+// signature: the response as compact JSON with object keys sorted
+// lexicographically, omitted fields left out, no insignificant whitespace,
+// strings escaped as encoding/json does (including <, > and & as \u003c,
+// \u003e and \u0026), and the "signature" field removed. The "signer" field
+// is included, so the claimed signer address is itself covered by the
+// signature.
+func canonicalMintResponse(resp MintResponse) ([]byte, error) {
+	resp.Signature = ""
+
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+// signMintResponse signs the canonical form of resp with the deployer key
+// using EIP-191 personal_sign, so partners can recover the signer with
+// ecrecover over keccak256("\x19Ethereum Signed Message:\n" + len + payload).
+func signMintResponse(net *network, resp *MintResponse) error {
+	resp.Signer = net.from.Hex()
+
+	payload, err := canonicalMintResponse(*resp)
+	if err != nil {
+		return fmt.Errorf("failed to canonicalize response: %v", err)
+	}
+
+	signature, err := net.signer.SignHash(accounts.TextHash(payload))
+	if err != nil {
+		return fmt.Errorf("failed to sign response: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	resp.Signature = hexutil.Encode(signature)
+	return nil
+}
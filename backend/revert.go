@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	revertHexPattern    = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+	revertReasonPattern = regexp.MustCompile(`reverted with reason string '(.*)'`)
+)
+
+// decodeRevertReason extracts a human-readable revert reason from an RPC
+// error. Providers disagree on where the revert data lives: geth and Infura
+// return it as a hex string in the error's data field, Alchemy sometimes nests
+// it in a {"data": ...} object, and some nodes only embed it in the message.
+func decodeRevertReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if reason, ok := revertReasonFromData(dataErr.ErrorData()); ok {
+			return reason
+		}
+	}
+
+	msg := err.Error()
+	if match := revertHexPattern.FindString(msg); match != "" {
+		if reason, ok := revertReasonFromHex(match); ok {
+			return reason
+		}
+	}
+	if match := revertReasonPattern.FindStringSubmatch(msg); match != nil {
+		return match[1]
+	}
+	if _, reason, found := strings.Cut(msg, "execution reverted: "); found {
+		return reason
+	}
+
+	return ""
+}
+
+func revertReasonFromData(data any) (string, bool) {
+	switch v := data.(type) {
+	case string:
+		return revertReasonFromHex(v)
+	case map[string]any:
+		if reason, ok := v["reason"].(string); ok && reason != "" {
+			return reason, true
+		}
+		for _, key := range []string{"data", "originalError"} {
+			if nested, ok := v[key]; ok {
+				if reason, ok := revertReasonFromData(nested); ok {
+					return reason, true
+				}
+			}
+		}
+		for _, nested := range v {
+			if inner, ok := nested.(map[string]any); ok {
+				if reason, ok := revertReasonFromData(inner); ok {
+					return reason, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func revertReasonFromHex(s string) (string, bool) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil || len(raw) < 4 {
+		return "", false
+	}
+	return unpackRevertData(raw)
+}
+
+func unpackRevertData(data []byte) (string, bool) {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason, true
+	}
+
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return "", false
+	}
+	for name, abiErr := range parsed.Errors {
+		if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+			continue
+		}
+		args, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			return name, true
+		}
+		values := make([]string, len(args))
+		for i, arg := range args {
+			values[i] = fmt.Sprint(arg)
+		}
+		return fmt.Sprintf("%s(%s)", name, strings.Join(values, ", ")), true
+	}
+	return "", false
+}
+
+// replayRevertReason re-executes a mined transaction against the state of its
+// parent block to recover the revert reason, which receipts do not carry.
+func replayRevertReason(net *network, tx *types.Transaction, receipt *types.Receipt) string {
+	msg := ethereum.CallMsg{
+		From:  net.from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	block := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+
+	_, err := net.client.CallContract(context.Background(), msg, block)
+	return decodeRevertReason(err)
+}
+
+func isRevertError(err error) bool {
+	return err != nil && (decodeRevertReason(err) != "" || strings.Contains(err.Error(), "revert"))
+}
+
+func withRevertReason(message string, reason string) string {
+	if reason == "" {
+		return message
+	}
+	return fmt.Sprintf("%s: %s", message, reason)
+}
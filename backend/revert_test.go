@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// rpcErrorPayload mirrors the JSON-RPC error object that go-ethereum's rpc
+// package surfaces as an rpc.DataError.
+type rpcErrorPayload struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcErrorPayload) Error() string  { return e.Message }
+func (e *rpcErrorPayload) ErrorCode() int { return e.Code }
+func (e *rpcErrorPayload) ErrorData() any { return e.Data }
+
+const (
+	// Error("Not an authorized minter")
+	revertErrorString = "0x08c379a0000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000184e6f7420616e20617574686f72697a6564206d696e7465720000000000000000"
+	// Panic(0x11)
+	revertPanicOverflow = "0x4e487b710000000000000000000000000000000000000000000000000000000000000011"
+	// OwnableUnauthorizedAccount(0x1111111111111111111111111111111111111111)
+	revertOwnableUnauthorized = "0x118cdaa70000000000000000000000001111111111111111111111111111111111111111"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "geth eth_call",
+			payload: `{"code":3,"message":"execution reverted: Not an authorized minter","data":"` + revertErrorString + `"}`,
+			want:    "Not an authorized minter",
+		},
+		{
+			name:    "infura without reason in message",
+			payload: `{"code":3,"message":"execution reverted","data":"` + revertErrorString + `"}`,
+			want:    "Not an authorized minter",
+		},
+		{
+			name:    "alchemy nested data",
+			payload: `{"code":-32000,"message":"execution reverted","data":{"data":"` + revertErrorString + `"}}`,
+			want:    "Not an authorized minter",
+		},
+		{
+			name:    "alchemy original error",
+			payload: `{"code":-32000,"message":"execution reverted","data":{"originalError":{"code":3,"data":"` + revertErrorString + `","message":"execution reverted"}}}`,
+			want:    "Not an authorized minter",
+		},
+		{
+			name:    "reason field",
+			payload: `{"code":-32000,"message":"execution reverted","data":{"reason":"Not an authorized minter"}}`,
+			want:    "Not an authorized minter",
+		},
+		{
+			name:    "panic arithmetic overflow",
+			payload: `{"code":3,"message":"execution reverted","data":"` + revertPanicOverflow + `"}`,
+			want:    "arithmetic underflow or overflow",
+		},
+		{
+			name:    "custom error in data",
+			payload: `{"code":3,"message":"execution reverted","data":"` + revertOwnableUnauthorized + `"}`,
+			want:    "OwnableUnauthorizedAccount(0x1111111111111111111111111111111111111111)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload rpcErrorPayload
+			if err := json.Unmarshal([]byte(tt.payload), &payload); err != nil {
+				t.Fatalf("bad payload: %v", err)
+			}
+			if got := decodeRevertReason(&payload); got != tt.want {
+				t.Errorf("decodeRevertReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRevertReasonFromMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"hex in message", errors.New("execution reverted: " + revertErrorString), "Not an authorized minter"},
+		{"hardhat reason string", errors.New("VM Exception while processing transaction: reverted with reason string 'Not an authorized minter'"), "Not an authorized minter"},
+		{"plain reason", errors.New("execution reverted: Not an authorized minter"), "Not an authorized minter"},
+		{"not a revert", errors.New("connection refused"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeRevertReason(tt.err); got != tt.want {
+				t.Errorf("decodeRevertReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
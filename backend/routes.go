@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	routeHealth      = "health"
+	routeStats       = "stats"
+	routeEventStream = "event-stream"
+)
+
+// newRouter builds the HTTP router. When ROUTE_PREFIX is set every route is
+// mounted under it; the health check stays at the prefix too unless
+// HEALTH_CHECK_BYPASS_PREFIX is set, in which case it is served at /health.
+func newRouter() (*mux.Router, error) {
+	prefix := strings.TrimRight(strings.TrimSpace(os.Getenv("ROUTE_PREFIX")), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("ROUTE_PREFIX must start with '/'")
+	}
+
+	bypassHealth, err := envBool("HEALTH_CHECK_BYPASS_PREFIX", false)
+	if err != nil {
+		return nil, err
+	}
+
+	root := mux.NewRouter()
+	root.Use(correlationMiddleware)
+	if shedder != nil {
+		root.Use(shedder.middleware)
+	}
+	root.Use(authMiddleware)
+
+	api := root
+	if prefix != "" {
+		api = root.PathPrefix(prefix).Subrouter()
+	}
+
+	if bypassHealth {
+		root.HandleFunc("/health", healthHandler).Methods("GET").Name(routeHealth)
+	} else {
+		api.HandleFunc("/health", healthHandler).Methods("GET").Name(routeHealth)
+	}
+
+	api.HandleFunc("/mint", mintTokensHandler).Methods("POST")
+	if featureEnabled(featureBatch) {
+		api.HandleFunc("/mint/batch", batchMintHandler).Methods("POST")
+	}
+	if featureEnabled(featureEstimate) {
+		api.HandleFunc("/mint/estimate", estimateMintHandler).Methods("POST")
+	}
+	api.HandleFunc("/mint/{jobId}", mintJobHandler).Methods("GET")
+	api.HandleFunc("/info", infoHandler).Methods("GET")
+	if featureEnabled(featureChecksum) {
+		api.HandleFunc("/checksum/{address}", checksumHandler).Methods("GET")
+	}
+	if featureEnabled(featureBalances) {
+		api.HandleFunc("/balance/{address}", balanceHandler).Methods("GET")
+		api.HandleFunc("/balances", balancesHandler).Methods("POST")
+		api.HandleFunc("/supply", supplyHandler).Methods("GET")
+		api.HandleFunc("/allowance/{owner}/{spender}", allowanceHandler).Methods("GET")
+	}
+	if featureEnabled(featureEvents) && tokenEvents != nil {
+		api.HandleFunc("/events", eventsHandler).Methods("GET")
+		api.HandleFunc("/events/stream", eventStreamHandler).Methods("GET").Name(routeEventStream)
+	}
+	if featureEnabled(featureHistory) && mintHistory != nil {
+		api.HandleFunc("/mints", listMintsHandler).Methods("GET")
+		api.HandleFunc("/mints/{txHash}", getMintHandler).Methods("GET")
+	}
+	if featureEnabled(featureStats) {
+		api.HandleFunc("/stats", statsHandler).Methods("GET").Name(routeStats)
+	}
+	api.HandleFunc("/status/{hash}", txStatusHandler).Methods("GET")
+	api.HandleFunc("/tx/{hash}", txStatusHandler).Methods("GET")
+	if featureEnabled(featureTxDecode) {
+		api.HandleFunc("/tx/{hash}/decode", decodeTxHandler).Methods("GET")
+	}
+
+	return root, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow map[int]bool
+}
+
+type scheduledRecipient struct {
+	net    *network
+	to     common.Address
+	sales  decimalAmount
+	amount *big.Int
+}
+
+type scheduledMintConfig struct {
+	schedule   *cronSchedule
+	recipients []scheduledRecipient
+	// caps holds SCHEDULED_MINT_MAX_PER_REQUEST and SCHEDULED_MINT_MAX_PER_DAY
+	// in the same form as an API key's, so scheduled mints are reserved and
+	// released against them exactly like requests to POST /mint.
+	caps *apiKey
+}
+
+func loadScheduledMintConfig() (*scheduledMintConfig, error) {
+	enabled, err := envBool("SCHEDULED_MINT_ENABLED", false)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	schedule, err := parseCron(os.Getenv("SCHEDULED_MINT_CRON"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULED_MINT_CRON: %v", err)
+	}
+
+	path := os.Getenv("SCHEDULED_MINT_RECIPIENTS_FILE")
+	if path == "" {
+		return nil, fmt.Errorf("SCHEDULED_MINT_RECIPIENTS_FILE must be set when SCHEDULED_MINT_ENABLED is true")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipients file: %v", err)
+	}
+
+	var requests []MintRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to parse recipients file: %v", err)
+	}
+
+	caps := &apiKey{name: "schedule", usedDay: new(big.Int)}
+	if caps.maxPerRequest, err = parseMintCap(os.Getenv("SCHEDULED_MINT_MAX_PER_REQUEST")); err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULED_MINT_MAX_PER_REQUEST: %v", err)
+	}
+	if caps.maxPerDay, err = parseMintCap(os.Getenv("SCHEDULED_MINT_MAX_PER_DAY")); err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULED_MINT_MAX_PER_DAY: %v", err)
+	}
+
+	recipients := make([]scheduledRecipient, len(requests))
+	for i, req := range requests {
+		amount, err := validateMintRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %d: %v", i, err)
+		}
+		if caps.maxPerRequest != nil && amount.Cmp(caps.maxPerRequest) > 0 {
+			return nil, fmt.Errorf("recipient %d: amount exceeds SCHEDULED_MINT_MAX_PER_REQUEST", i)
+		}
+		net, err := networkFor(req.Network)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %d: %v", i, err)
+		}
+		recipients[i] = scheduledRecipient{net: net, to: common.HexToAddress(req.Company), sales: req.Sales, amount: amount}
+	}
+
+	return &scheduledMintConfig{schedule: schedule, recipients: recipients, caps: caps}, nil
+}
+
+func runScheduledMints(cfg *scheduledMintConfig) {
+	for {
+		next := cfg.schedule.next(time.Now())
+		log.Printf("Scheduled mint: next run at %s", next.Format(time.RFC3339))
+		time.Sleep(time.Until(next))
+
+		for _, recipient := range cfg.recipients {
+			status, resp := mintScheduled(cfg, recipient)
+			log.Printf("Scheduled mint to %s: status=%d success=%t tx=%s message=%q",
+				recipient.to.Hex(), status, resp.Success, resp.TxHash, resp.Message)
+		}
+	}
+}
+
+// mintScheduled mints to one recipient of a scheduled run, skipping it when
+// the schedule's caps are used up.
+func mintScheduled(cfg *scheduledMintConfig, recipient scheduledRecipient) (int, MintResponse) {
+	ctx := context.WithValue(context.Background(), apiKeyContextKey{}, cfg.caps)
+	if message := reserveMint(ctx, recipient.amount); message != "" {
+		return http.StatusTooManyRequests, MintResponse{Message: message}
+	}
+
+	status, resp := mintTo(recipient.net, recipient.to, recipient.amount)
+	if !resp.mayBeOnChain() {
+		releaseMint(ctx, recipient.amount)
+	}
+	recordMint("schedule", recipient.net, recipient.to, recipient.sales, recipient.amount, resp)
+	return status, resp
+}
+
+// parseCron parses a standard five-field cron expression
+// (minute hour day-of-month month day-of-week). Each field accepts "*",
+// single values, ranges ("1-5"), steps ("*/15", "0-30/10") and
+// comma-separated lists of those.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	sets := make([]map[int]bool, 5)
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", field, err)
+		}
+		sets[i] = set
+	}
+
+	return &cronSchedule{minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4]}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loPart); err != nil {
+				return nil, fmt.Errorf("invalid value %q", loPart)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiPart); err != nil {
+					return nil, fmt.Errorf("invalid value %q", hiPart)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value out of range %d-%d", min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func (c *cronSchedule) matches(t time.Time) bool {
+	return c.minute[t.Minute()] && c.hour[t.Hour()] && c.dom[t.Day()] &&
+		c.month[int(t.Month())] && c.dow[int(t.Weekday())]
+}
+
+// next returns the first minute strictly after t that matches the schedule.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	candidate := t.Truncate(time.Minute).Add(time.Minute)
+	limit := candidate.AddDate(5, 0, 0)
+	for candidate.Before(limit) {
+		if c.matches(candidate) {
+			return candidate
+		}
+		candidate = candidate.Add(time.Minute)
+	}
+	return limit
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMintScheduledAppliesDailyCap(t *testing.T) {
+	net, broadcaster := newMintTestNetwork(t, mintNodeHandlers(), 7)
+	cfg := &scheduledMintConfig{caps: &apiKey{name: "schedule", maxPerDay: big.NewInt(15e17), usedDay: new(big.Int)}}
+	recipient := func(to common.Address) scheduledRecipient {
+		return scheduledRecipient{net: net, to: to, sales: "1", amount: big.NewInt(1e18)}
+	}
+
+	if status, resp := mintScheduled(cfg, recipient(testRecipient)); status != http.StatusOK || !resp.Success {
+		t.Fatalf("first scheduled mint = %d, %+v", status, resp)
+	}
+	status, resp := mintScheduled(cfg, recipient(common.HexToAddress("0x3333333333333333333333333333333333333333")))
+	if status != http.StatusTooManyRequests || resp.TxHash != "" {
+		t.Errorf("mint over the daily cap = %d, %+v; want it skipped", status, resp)
+	}
+	if sent := broadcaster.sent(); len(sent) != 1 {
+		t.Errorf("broadcast %d transactions, want 1", len(sent))
+	}
+	if cfg.caps.usedDay.Int64() != 1e18 {
+		t.Errorf("daily cap used = %s, want 1e18", cfg.caps.usedDay)
+	}
+}
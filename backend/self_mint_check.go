@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testnetChains are the chains the self-mint check runs on without
+// SELF_MINT_CHECK_ALLOW_MAINNET. Anything else, including chains this list
+// does not know about, is treated as a production network.
+var testnetChains = map[uint64]string{
+	97:       "BNB Smart Chain testnet",
+	1337:     "local development chain",
+	17000:    "Holesky",
+	31337:    "Anvil/Hardhat",
+	43113:    "Avalanche Fuji",
+	80002:    "Polygon Amoy",
+	84532:    "Base Sepolia",
+	421614:   "Arbitrum Sepolia",
+	11155111: "Sepolia",
+	11155420: "OP Sepolia",
+}
+
+type selfMintCheckConfig struct {
+	interval time.Duration
+	target   common.Address
+	amount   *big.Int
+}
+
+// selfMintStatus is the synthetic-monitoring gauge: 1 when the last
+// self-mint succeeded, 0 when it failed, -1 before the first run.
+var (
+	selfMintStatus  atomic.Int64
+	selfMintLastRun atomic.Int64
+)
+
+func loadSelfMintCheckConfig() (*selfMintCheckConfig, error) {
+	enabled, err := envBool("SELF_MINT_CHECK_ENABLED", false)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	allowMainnet, err := envBool("SELF_MINT_CHECK_ALLOW_MAINNET", false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSelfMintChain(connectedChainID, os.Getenv("SELF_MINT_CHECK_TESTNET_CHAIN_IDS"), allowMainnet); err != nil {
+		return nil, err
+	}
+
+	target := os.Getenv("SELF_MINT_CHECK_TARGET")
+	if !common.IsHexAddress(target) {
+		return nil, fmt.Errorf("SELF_MINT_CHECK_TARGET must be a valid address")
+	}
+
+	interval, err := envDuration("SELF_MINT_CHECK_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("SELF_MINT_CHECK_INTERVAL must be positive")
+	}
+
+	amountValue := os.Getenv("SELF_MINT_CHECK_AMOUNT")
+	if amountValue == "" {
+		amountValue = "0.000001"
+	}
+	amount, err := parseUnits(amountValue, tokenDecimals)
+	if err != nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid SELF_MINT_CHECK_AMOUNT %q", amountValue)
+	}
+
+	return &selfMintCheckConfig{interval: interval, target: common.HexToAddress(target), amount: amount}, nil
+}
+
+// checkSelfMintChain allows the self-mint check on known testnets and on the
+// extra comma-separated chain IDs in SELF_MINT_CHECK_TESTNET_CHAIN_IDS
+// (private chains, for instance), and elsewhere only when allowMainnet is set.
+func checkSelfMintChain(chainID *big.Int, extraTestnets string, allowMainnet bool) error {
+	if allowMainnet {
+		return nil
+	}
+	if chainID.IsUint64() {
+		if _, ok := testnetChains[chainID.Uint64()]; ok {
+			return nil
+		}
+	}
+	if strings.TrimSpace(extraTestnets) != "" {
+		ids, err := parseChainIDs("SELF_MINT_CHECK_TESTNET_CHAIN_IDS", extraTestnets)
+		if err != nil {
+			return err
+		}
+		if containsChainID(ids, chainID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("self-mint check only runs on testnets; chain ID %s is not a known testnet (set SELF_MINT_CHECK_TESTNET_CHAIN_IDS, or SELF_MINT_CHECK_ALLOW_MAINNET to override)", chainID)
+}
+
+func runSelfMintCheck(cfg *selfMintCheckConfig) {
+	ticker := time.NewTicker(cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		_, resp := submitMint(primaryNetwork, cfg.target, cfg.amount)
+		selfMintLastRun.Store(time.Now().Unix())
+		if resp.Success {
+			selfMintStatus.Store(1)
+			log.Printf("Self-mint check succeeded: tx=%s", resp.TxHash)
+		} else {
+			selfMintStatus.Store(0)
+			log.Printf("Self-mint check FAILED: %s", resp.Message)
+		}
+
+		<-ticker.C
+	}
+}
+
+func init() {
+	selfMintStatus.Store(-1)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCheckSelfMintChain(t *testing.T) {
+	tests := []struct {
+		name         string
+		chainID      int64
+		extra        string
+		allowMainnet bool
+		wantErr      bool
+	}{
+		{name: "sepolia", chainID: 11155111},
+		{name: "anvil", chainID: 31337},
+		{name: "ethereum mainnet", chainID: 1, wantErr: true},
+		{name: "polygon", chainID: 137, wantErr: true},
+		{name: "arbitrum one", chainID: 42161, wantErr: true},
+		{name: "unknown chain", chainID: 99999, wantErr: true},
+		{name: "unknown chain listed as testnet", chainID: 99999, extra: "12345, 99999"},
+		{name: "invalid extra list", chainID: 99999, extra: "abc", wantErr: true},
+		{name: "mainnet with override", chainID: 1, allowMainnet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSelfMintChain(big.NewInt(tt.chainID), tt.extra, tt.allowMainnet)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSelfMintChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
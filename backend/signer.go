@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// unprotectedChains are the local development chains where DISABLE_EIP155
+// is accepted as is. Any other chain has to be listed in
+// DISABLE_EIP155_CHAIN_IDS: public networks either reject unprotected
+// (pre-EIP-155) transactions or let anyone replay them elsewhere.
+var unprotectedChains = map[uint64]string{
+	1337:  "local development chain",
+	31337: "Anvil/Hardhat",
+}
+
+var disableEIP155 bool
+
+func loadSignerConfig(chainID *big.Int) error {
+	disable, err := envBool("DISABLE_EIP155", false)
+	if err != nil {
+		return err
+	}
+	if disable {
+		if err := checkUnprotectedChain(chainID, os.Getenv("DISABLE_EIP155_CHAIN_IDS")); err != nil {
+			return err
+		}
+	}
+	disableEIP155 = disable
+	return nil
+}
+
+// checkUnprotectedChain allows unprotected signing on the local development
+// chains and on the extra comma-separated chain IDs in extraChains.
+func checkUnprotectedChain(chainID *big.Int, extraChains string) error {
+	if chainID.IsUint64() {
+		if _, ok := unprotectedChains[chainID.Uint64()]; ok {
+			return nil
+		}
+	}
+	if strings.TrimSpace(extraChains) != "" {
+		ids, err := parseChainIDs("DISABLE_EIP155_CHAIN_IDS", extraChains)
+		if err != nil {
+			return err
+		}
+		if containsChainID(ids, chainID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("DISABLE_EIP155 is only allowed on local development chains; chain ID %s is not one (list it in DISABLE_EIP155_CHAIN_IDS to allow it)", chainID)
+}
+
+// txSigner picks the signer used for outgoing transactions: unprotected
+// Homestead signing when DISABLE_EIP155 is set, otherwise the latest signer
+// for the chain ID.
+func txSigner(chainID *big.Int, unprotected bool) types.Signer {
+	if unprotected {
+		return types.HomesteadSigner{}
+	}
+	return types.LatestSignerForChainID(chainID)
+}
+
+// newTransactor returns transact options whose SignerFn signs through the
+// network's Signer backend.
+func newTransactor(net *network) (*bind.TransactOpts, error) {
+	signer := txSigner(net.chainID, disableEIP155)
+	from := net.from
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			signature, err := net.signer.SignHash(signer.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, signature)
+		},
+		Context: context.Background(),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer holds the deployer key, wherever it lives. SignHash returns a
+// 65-byte [R || S || V] signature with V in {0, 1}, as crypto.Sign does.
+// Nothing outside the signer backends touches key material; transactions
+// are signed through the bind.SignerFn built by newTransactor.
+type Signer interface {
+	Address() common.Address
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// signerBackends builds a signer from environment variables, each read with
+// the given prefix so that networks can use different keys.
+var signerBackends = map[string]func(prefix string) (Signer, error){
+	"env":      loadEnvKeySigner,
+	"keystore": loadKeystoreSigner,
+	"kms":      loadKMSSigner,
+	"vault": func(string) (Signer, error) {
+		return nil, fmt.Errorf("SIGNER_TYPE=vault is not supported: Vault's transit engine has no secp256k1 key type, so it cannot produce Ethereum signatures")
+	},
+}
+
+// loadSigner picks the signer backend from <prefix>SIGNER_TYPE, defaulting
+// to the <prefix>PRIVATE_KEY environment variable.
+func loadSigner(prefix string) (Signer, error) {
+	name := strings.ToLower(os.Getenv(prefix + "SIGNER_TYPE"))
+	if name == "" {
+		name = "env"
+	}
+
+	factory, ok := signerBackends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown %sSIGNER_TYPE %q", prefix, name)
+	}
+	return factory(prefix)
+}
+
+// localKeySigner signs with a key held in process memory.
+type localKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func newLocalKeySigner(key *ecdsa.PrivateKey) *localKeySigner {
+	return &localKeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (s *localKeySigner) Address() common.Address { return s.address }
+
+func (s *localKeySigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+func loadEnvKeySigner(prefix string) (Signer, error) {
+	privateKeyHex := os.Getenv(prefix + "PRIVATE_KEY")
+	if privateKeyHex == "" {
+		return nil, fmt.Errorf("%sPRIVATE_KEY environment variable is not set", prefix)
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return newLocalKeySigner(key), nil
+}
+
+// loadKeystoreSigner decrypts a geth keystore (V3 JSON) file named by
+// KEYSTORE_PATH. The passphrase comes from KEYSTORE_PASSPHRASE_FILE, or
+// KEYSTORE_PASSPHRASE when no file is given.
+func loadKeystoreSigner(prefix string) (Signer, error) {
+	path := os.Getenv(prefix + "KEYSTORE_PATH")
+	if path == "" {
+		return nil, fmt.Errorf("%sKEYSTORE_PATH must be set when %sSIGNER_TYPE is keystore", prefix, prefix)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %v", err)
+	}
+
+	passphrase := os.Getenv(prefix + "KEYSTORE_PASSPHRASE")
+	if passphraseFile := os.Getenv(prefix + "KEYSTORE_PASSPHRASE_FILE"); passphraseFile != "" {
+		contents, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore passphrase file: %v", err)
+		}
+		passphrase = strings.TrimRight(string(contents), "\r\n")
+	}
+
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %v", err)
+	}
+	return newLocalKeySigner(key.PrivateKey), nil
+}
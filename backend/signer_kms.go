@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// kmsSigner signs with an AWS KMS asymmetric key of spec ECC_SECG_P256K1.
+// Requests are signed with SigV4 using the standard AWS_* credential
+// variables; the key never leaves KMS.
+type kmsSigner struct {
+	endpoint     string
+	region       string
+	keyID        string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+
+	publicKey []byte
+	address   common.Address
+}
+
+type kmsSignature struct {
+	R, S *big.Int
+}
+
+type kmsPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+func loadKMSSigner(prefix string) (Signer, error) {
+	s := &kmsSigner{
+		region:       os.Getenv(prefix + "AWS_REGION"),
+		keyID:        os.Getenv(prefix + "KMS_KEY_ID"),
+		accessKey:    os.Getenv(prefix + "AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv(prefix + "AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv(prefix + "AWS_SESSION_TOKEN"),
+		endpoint:     os.Getenv(prefix + "KMS_ENDPOINT"),
+	}
+	if s.region == "" || s.keyID == "" || s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("%sSIGNER_TYPE=kms requires %[1]sAWS_REGION, %[1]sKMS_KEY_ID, %[1]sAWS_ACCESS_KEY_ID and %[1]sAWS_SECRET_ACCESS_KEY", prefix)
+	}
+	if s.endpoint == "" {
+		s.endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", s.region)
+	}
+
+	var resp struct {
+		PublicKey string
+		KeySpec   string
+	}
+	if err := s.call("GetPublicKey", map[string]string{"KeyId": s.keyID}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.KeySpec != "" && resp.KeySpec != "ECC_SECG_P256K1" {
+		return nil, fmt.Errorf("KMS key %s has spec %s, need ECC_SECG_P256K1", s.keyID, resp.KeySpec)
+	}
+
+	der, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS public key encoding: %v", err)
+	}
+	var info kmsPublicKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("invalid KMS public key: %v", err)
+	}
+	pub, err := crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("KMS public key is not a secp256k1 point: %v", err)
+	}
+
+	s.publicKey = info.PublicKey.Bytes
+	s.address = crypto.PubkeyToAddress(*pub)
+	return s, nil
+}
+
+func (s *kmsSigner) Address() common.Address { return s.address }
+
+// SignHash asks KMS to sign the digest, then converts the DER signature to
+// Ethereum's form: S is normalised to the lower half of the curve order and
+// the recovery ID is found by trying both candidates.
+func (s *kmsSigner) SignHash(hash []byte) ([]byte, error) {
+	var resp struct{ Signature string }
+	err := s.call("Sign", map[string]string{
+		"KeyId":            s.keyID,
+		"Message":          base64.StdEncoding.EncodeToString(hash),
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := base64.StdEncoding.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMS signature encoding: %v", err)
+	}
+	var parsed kmsSignature
+	if _, err := asn1.Unmarshal(der, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid KMS signature: %v", err)
+	}
+
+	n := crypto.S256().Params().N
+	if parsed.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		parsed.S.Sub(n, parsed.S)
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	parsed.R.FillBytes(sig[:32])
+	parsed.S.FillBytes(sig[32:64])
+	for v := byte(0); v < 2; v++ {
+		sig[crypto.RecoveryIDOffset] = v
+		if pub, err := crypto.Ecrecover(hash, sig); err == nil && bytes.Equal(pub, s.publicKey) {
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("KMS signature does not recover to %s", s.address.Hex())
+}
+
+func (s *kmsSigner) call(action string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid KMS endpoint: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	s.signRequest(req, body, time.Now().UTC())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %v", action, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %v", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("KMS %s returned status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid KMS %s response: %v", action, err)
+	}
+	return nil
+}
+
+// signRequest adds an AWS Signature Version 4 Authorization header.
+func (s *kmsSigner) signRequest(req *http.Request, body []byte, now time.Time) {
+	signV4(req, body, now, s.region, "kms", s.accessKey, s.secretKey, s.sessionToken)
+}
+
+// signV4 signs req for service in region. It covers every header already on
+// the request, so anything added afterwards is not signed.
+func signV4(req *http.Request, body []byte, now time.Time, region, service, accessKey, secretKey, sessionToken string) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The SigV4 cases below are from the AWS Signature Version 4 test suite and
+// the IAM ListUsers example in the AWS documentation, which all sign with
+// these example credentials at 2015-08-30T12:36:00Z.
+const (
+	sigV4TestAccessKey = "AKIDEXAMPLE"
+	sigV4TestSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+func TestSignV4(t *testing.T) {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		service string
+		want    string
+	}{
+		{
+			name:    "get-vanilla",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:    "post-vanilla",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:    "get-vanilla-query-order-key-case",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:    "post-x-www-form-urlencoded",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:    "Param1=value1",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:    "iam ListUsers",
+			method:  http.MethodGet,
+			url:     "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			service: "iam",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			signV4(req, []byte(tt.body), now, "us-east-1", tt.service, sigV4TestAccessKey, sigV4TestSecretKey, "")
+
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization =\n  %s\nwant\n  %s", got, tt.want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s", got)
+			}
+		})
+	}
+}
+
+func TestSignV4SignsSessionToken(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://kms.us-east-1.amazonaws.com/", nil)
+	signV4(req, nil, time.Now().UTC(), "us-east-1", "kms", sigV4TestAccessKey, sigV4TestSecretKey, "session")
+
+	if req.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Error("session token header not set")
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("session token not signed: %s", req.Header.Get("Authorization"))
+	}
+}
+
+// fakeKMS answers GetPublicKey and Sign for key with a local private key,
+// returning signatures in the DER form KMS uses. When highS is set it
+// returns the equally valid signature with S in the upper half of the
+// curve order, which KMS is free to do.
+type fakeKMS struct {
+	t     *testing.T
+	key   *ecdsa.PrivateKey
+	highS bool
+}
+
+func (k *fakeKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		http.Error(w, `{"__type":"MissingAuthenticationTokenException"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		KeyId       string
+		Message     string
+		MessageType string
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.KeyId != "alias/minter" {
+		http.Error(w, `{"__type":"NotFoundException"}`, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "TrentService.GetPublicKey":
+		// SubjectPublicKeyInfo for id-ecPublicKey on secp256k1.
+		curve, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+		der, err := asn1.Marshal(kmsPublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+				Parameters: asn1.RawValue{FullBytes: curve},
+			},
+			PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(&k.key.PublicKey), BitLength: 65 * 8},
+		})
+		if err != nil {
+			k.t.Error(err)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"KeyId":     req.KeyId,
+			"KeySpec":   "ECC_SECG_P256K1",
+			"PublicKey": base64.StdEncoding.EncodeToString(der),
+		})
+	case "TrentService.Sign":
+		digest, _ := base64.StdEncoding.DecodeString(req.Message)
+		if req.MessageType != "DIGEST" || len(digest) != 32 {
+			http.Error(w, `{"__type":"ValidationException"}`, http.StatusBadRequest)
+			return
+		}
+		sig, err := crypto.Sign(digest, k.key)
+		if err != nil {
+			k.t.Error(err)
+			return
+		}
+		parsed := kmsSignature{R: new(big.Int).SetBytes(sig[:32]), S: new(big.Int).SetBytes(sig[32:64])}
+		if k.highS {
+			parsed.S.Sub(crypto.S256().Params().N, parsed.S)
+		}
+		der, err := asn1.Marshal(parsed)
+		if err != nil {
+			k.t.Error(err)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Signature": base64.StdEncoding.EncodeToString(der)})
+	default:
+		http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+	}
+}
+
+func TestKMSSignerRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := crypto.PubkeyToAddress(key.PublicKey)
+
+	for _, highS := range []bool{false, true} {
+		kms := &fakeKMS{t: t, key: key, highS: highS}
+		server := httptest.NewServer(kms)
+		defer server.Close()
+
+		t.Setenv("AWS_REGION", "us-east-1")
+		t.Setenv("KMS_KEY_ID", "alias/minter")
+		t.Setenv("AWS_ACCESS_KEY_ID", sigV4TestAccessKey)
+		t.Setenv("AWS_SECRET_ACCESS_KEY", sigV4TestSecretKey)
+		t.Setenv("KMS_ENDPOINT", server.URL)
+
+		signer, err := loadKMSSigner("")
+		if err != nil {
+			t.Fatalf("highS=%v: loadKMSSigner() error = %v", highS, err)
+		}
+		if signer.Address() != want {
+			t.Fatalf("highS=%v: address = %s, want %s", highS, signer.Address().Hex(), want.Hex())
+		}
+
+		for i := 0; i < 8; i++ {
+			hash := crypto.Keccak256([]byte{byte(i)})
+			sig, err := signer.SignHash(hash)
+			if err != nil {
+				t.Fatalf("highS=%v: SignHash() error = %v", highS, err)
+			}
+			if len(sig) != crypto.SignatureLength || sig[crypto.RecoveryIDOffset] > 1 {
+				t.Fatalf("highS=%v: signature %x is not [R || S || V] with V in {0, 1}", highS, sig)
+			}
+			if new(big.Int).SetBytes(sig[32:64]).Cmp(new(big.Int).Rsh(crypto.S256().Params().N, 1)) > 0 {
+				t.Errorf("highS=%v: S was not normalised to the lower half", highS)
+			}
+			pub, err := crypto.SigToPub(hash, sig)
+			if err != nil || crypto.PubkeyToAddress(*pub) != want {
+				t.Errorf("highS=%v: signature recovers to %v (%v), want %s", highS, pub, err, want.Hex())
+			}
+			local, _ := crypto.Sign(hash, key)
+			if string(sig) != string(local) {
+				t.Errorf("highS=%v: signature %x differs from local signature %x", highS, sig, local)
+			}
+		}
+	}
+}
+
+func TestKMSSignerRejectsWrongKeySpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"KeySpec": "ECC_NIST_P256", "PublicKey": ""})
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("KMS_KEY_ID", "alias/minter")
+	t.Setenv("AWS_ACCESS_KEY_ID", sigV4TestAccessKey)
+	t.Setenv("AWS_SECRET_ACCESS_KEY", sigV4TestSecretKey)
+	t.Setenv("KMS_ENDPOINT", server.URL)
+
+	if _, err := loadKMSSigner(""); err == nil || !strings.Contains(err.Error(), "ECC_SECG_P256K1") {
+		t.Errorf("loadKMSSigner() error = %v, want a key spec error", err)
+	}
+}
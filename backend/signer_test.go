@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTxSigner(t *testing.T) {
+	chainID := big.NewInt(1337)
+
+	if _, ok := txSigner(chainID, true).(types.HomesteadSigner); !ok {
+		t.Errorf("unprotected signer = %T, want types.HomesteadSigner", txSigner(chainID, true))
+	}
+
+	signer := txSigner(chainID, false)
+	if signer.ChainID().Cmp(chainID) != 0 {
+		t.Errorf("protected signer chain ID = %s, want %s", signer.ChainID(), chainID)
+	}
+	if !signer.Equal(types.LatestSignerForChainID(chainID)) {
+		t.Errorf("protected signer = %T, want the latest signer for chain %s", signer, chainID)
+	}
+}
+
+func TestLoadSignerConfig(t *testing.T) {
+	keepGlobal(t, &disableEIP155)
+
+	tests := []struct {
+		name    string
+		disable string
+		extra   string
+		chainID int64
+		wantErr bool
+	}{
+		{"default on mainnet", "", "", 1, false},
+		{"disabled on local chain", "true", "", 1337, false},
+		{"disabled on anvil", "true", "", 31337, false},
+		{"disabled on mainnet", "true", "", 1, true},
+		{"disabled on gnosis", "true", "", 100, true},
+		{"disabled on sepolia", "true", "", 11155111, true},
+		{"disabled on unlisted private chain", "true", "", 424242, true},
+		{"disabled on listed private chain", "true", "424242, 777", 424242, false},
+		{"list ignored while enabled", "", "not a number", 1, false},
+		{"invalid chain list", "true", "abc", 424242, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disableEIP155 = false
+			t.Setenv("DISABLE_EIP155", tt.disable)
+			t.Setenv("DISABLE_EIP155_CHAIN_IDS", tt.extra)
+			err := loadSignerConfig(big.NewInt(tt.chainID))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadSignerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := err == nil && tt.disable == "true"
+			if disableEIP155 != want {
+				t.Errorf("disableEIP155 = %v, want %v", disableEIP155, want)
+			}
+		})
+	}
+}
+
+func TestNewTransactorSignsForChain(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := newLocalKeySigner(key)
+	net := &network{chainID: big.NewInt(1337), signer: signer, from: signer.Address()}
+	keepGlobal(t, &disableEIP155)
+
+	for _, unprotected := range []bool{false, true} {
+		disableEIP155 = unprotected
+		auth, err := newTransactor(net)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+		signed, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signed.Protected() == unprotected {
+			t.Errorf("unprotected=%v: tx.Protected() = %v", unprotected, signed.Protected())
+		}
+		sender, err := types.Sender(txSigner(net.chainID, unprotected), signed)
+		if err != nil || sender != signer.Address() {
+			t.Errorf("unprotected=%v: sender = %s (%v), want %s", unprotected, sender.Hex(), err, signer.Address().Hex())
+		}
+	}
+	disableEIP155 = false
+
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	auth, _ := newTransactor(net)
+	if _, err := auth.Signer(other, types.NewTx(&types.LegacyTx{})); err == nil {
+		t.Error("signing for another address succeeded")
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// simulateMint executes mint_secure via eth_call against pending state
+// without broadcasting and returns the gas the call is expected to use.
+func simulateMint(net *network, to common.Address, amount *big.Int) (uint64, error) {
+	parsed, err := TokenMetaData.GetAbi()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load contract ABI: %v", err)
+	}
+
+	data, err := parsed.Pack("mint_secure", to, amount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode mint call: %v", err)
+	}
+
+	msg := ethereum.CallMsg{From: net.from, To: &net.contractAddr, Data: data}
+	if _, err := net.client.PendingCallContract(context.Background(), msg); err != nil {
+		return 0, err
+	}
+
+	return net.client.EstimateGas(context.Background(), msg)
+}
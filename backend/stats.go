@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const recentOutcomeWindow = 100
+
+type serviceStats struct {
+	inFlightMints  atomic.Int64
+	pendingTxs     atomic.Int64
+	mintsSucceeded atomic.Int64
+	mintsFailed    atomic.Int64
+
+	mu      sync.Mutex
+	recent  [recentOutcomeWindow]bool
+	next    int
+	samples int
+}
+
+type StatsResponse struct {
+	InFlightMints     int64   `json:"inFlightMints"`
+	PendingTxs        int64   `json:"pendingTransactions"`
+	ConfirmQueueDepth int     `json:"confirmQueueDepth"`
+	MintsSucceeded    int64   `json:"mintsSucceeded"`
+	MintsFailed       int64   `json:"mintsFailed"`
+	RecentSamples     int     `json:"recentSamples"`
+	RecentSuccessRate float64 `json:"recentSuccessRate"`
+	RecentErrorRate   float64 `json:"recentErrorRate"`
+	LoadShedding      bool    `json:"loadShedding"`
+	RequestsShed      int64   `json:"requestsShed"`
+	MaxReorgDepth     uint64  `json:"maxReorgDepth"`
+	SelfMintStatus    int64   `json:"selfMintStatus"`
+	SelfMintLastRun   string  `json:"selfMintLastRun,omitempty"`
+	Timestamp         string  `json:"timestamp"`
+}
+
+var stats serviceStats
+
+func (s *serviceStats) recordOutcome(success bool) {
+	if success {
+		s.mintsSucceeded.Add(1)
+	} else {
+		s.mintsFailed.Add(1)
+	}
+
+	s.mu.Lock()
+	s.recent[s.next] = success
+	s.next = (s.next + 1) % recentOutcomeWindow
+	if s.samples < recentOutcomeWindow {
+		s.samples++
+	}
+	s.mu.Unlock()
+}
+
+func (s *serviceStats) recentRates() (int, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples == 0 {
+		return 0, 0
+	}
+	succeeded := 0
+	for i := 0; i < s.samples; i++ {
+		if s.recent[i] {
+			succeeded++
+		}
+	}
+	return s.samples, float64(succeeded) / float64(s.samples)
+}
+
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	samples, successRate := stats.recentRates()
+	errorRate := 0.0
+	if samples > 0 {
+		errorRate = 1 - successRate
+	}
+
+	resp := StatsResponse{
+		InFlightMints:     stats.inFlightMints.Load(),
+		PendingTxs:        stats.pendingTxs.Load(),
+		ConfirmQueueDepth: len(confirmQueue),
+		MintsSucceeded:    stats.mintsSucceeded.Load(),
+		MintsFailed:       stats.mintsFailed.Load(),
+		RecentSamples:     samples,
+		RecentSuccessRate: successRate,
+		RecentErrorRate:   errorRate,
+		MaxReorgDepth:     maxReorgDepth.Load(),
+		SelfMintStatus:    selfMintStatus.Load(),
+		Timestamp:         time.Now().UTC().Format(time.RFC3339),
+	}
+	if lastRun := selfMintLastRun.Load(); lastRun != 0 {
+		resp.SelfMintLastRun = time.Unix(lastRun, 0).UTC().Format(time.RFC3339)
+	}
+	if shedder != nil {
+		resp.LoadShedding = shedder.shedding()
+		resp.RequestsShed = shedder.shed.Load()
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// jsonlFile is the append-only JSON-lines file behind the file-backed
+// stores. Each store replays it on start-up to rebuild its in-memory state
+// and appends every change afterwards; the file is the only state. Callers
+// serialise appends with their own lock.
+type jsonlFile[T any] struct {
+	path string
+	name string
+	file *os.File
+}
+
+// openJSONLFile opens path for appending, creating it if needed, and passes
+// every line to replay in order. name describes the file in errors.
+func openJSONLFile[T any](path, name string, replay func(T)) (*jsonlFile[T], error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", name, err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record T
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s line %d: %v", name, line, err)
+		}
+		replay(record)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return &jsonlFile[T]{path: path, name: name, file: file}, nil
+}
+
+func (f *jsonlFile[T]) append(record T) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %v", f.name, err)
+	}
+	return nil
+}
+
+func (f *jsonlFile[T]) close() error {
+	return f.file.Close()
+}
+
+// compact replaces the file with just records, written to a temporary file
+// and renamed over the original so a crash leaves one or the other intact.
+func (f *jsonlFile[T]) compact(records []T) error {
+	tmp := f.path + ".tmp"
+	compacted, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact %s: %v", f.name, err)
+	}
+	writer := bufio.NewWriter(compacted)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			compacted.Close()
+			return err
+		}
+		writer.Write(append(line, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		compacted.Close()
+		return fmt.Errorf("failed to compact %s: %v", f.name, err)
+	}
+	if err := compacted.Close(); err != nil {
+		return fmt.Errorf("failed to compact %s: %v", f.name, err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to compact %s: %v", f.name, err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", f.name, err)
+	}
+	f.file.Close()
+	f.file = file
+	return nil
+}
+
+// runPruneLoop calls prune ten times per retention window, but at most once
+// a second, for as long as the process runs.
+func runPruneLoop(retention time.Duration, prune func()) {
+	interval := retention / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		prune()
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRecord struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+func replayAll(t *testing.T, path string) []testRecord {
+	t.Helper()
+	var records []testRecord
+	file, err := openJSONLFile(path, "test file", func(r testRecord) { records = append(records, r) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.close()
+	return records
+}
+
+func TestJSONLFileAppendReplayCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+
+	file, err := openJSONLFile(path, "test file", func(testRecord) { t.Error("new file replayed a record") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"a", "b", "a"} {
+		if err := file.append(testRecord{ID: id, Value: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := replayAll(t, path); len(got) != 3 || got[2] != (testRecord{"a", 2}) {
+		t.Fatalf("replayed %+v, want the three appended lines in order", got)
+	}
+
+	if err := file.compact([]testRecord{{"b", 1}, {"a", 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.append(testRecord{ID: "c", Value: 3}); err != nil {
+		t.Fatal(err)
+	}
+	file.close()
+
+	got := replayAll(t, path)
+	want := []testRecord{{"b", 1}, {"a", 2}, {"c", 3}}
+	if len(got) != len(want) {
+		t.Fatalf("replayed %+v after compaction, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestJSONLFileRejectsCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	if err := os.WriteFile(path, []byte("{\"id\":\"a\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := openJSONLFile(path, "test file", func(testRecord) {})
+	if err == nil || !strings.HasPrefix(err.Error(), "test file line 2:") {
+		t.Errorf("openJSONLFile() error = %v, want a line 2 error", err)
+	}
+}
+
+func TestIdempotencyStoreCompactsOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.jsonl")
+	first := &idempotencyStore{retention: time.Hour, entries: make(map[string]*idempotencyEntry)}
+	if err := first.open(path); err != nil {
+		t.Fatal(err)
+	}
+	first.claim("kept", "f1")
+	first.complete("kept", 202, MintResponse{Success: true, TxHash: "0x01"})
+	first.claim("released", "f2")
+	first.release("released")
+	first.write(idempotencyEntry{Key: "expired", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	first.file.close()
+
+	second := &idempotencyStore{retention: time.Hour, entries: make(map[string]*idempotencyEntry)}
+	if err := second.open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer second.file.close()
+	if len(second.entries) != 1 || second.entries["kept"] == nil || second.entries["kept"].Response == nil {
+		t.Fatalf("entries after reopen = %v, want only the completed key", second.entries)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("compacted file has %d lines, want 1", lines)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gorilla/mux"
+)
+
+const (
+	txStatusPending   = "pending"
+	txStatusConfirmed = "confirmed"
+	txStatusFailed    = "failed"
+	// txStatusUnknown is a mint whose receipt was not seen in time. It was
+	// broadcast and may still be mined, so it is not reported as failed.
+	txStatusUnknown = "unknown"
+)
+
+type TrackedTx struct {
+	JobID       string        `json:"jobId,omitempty"`
+	Network     string        `json:"network,omitempty"`
+	TxHash      string        `json:"txHash"`
+	Status      string        `json:"status"`
+	Nonce       uint64        `json:"nonce"`
+	Company     string        `json:"company"`
+	Amount      string        `json:"amount"`
+	SubmittedAt time.Time     `json:"submittedAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	Result      *MintResponse `json:"result,omitempty"`
+}
+
+// txTracker records mints that were broadcast without waiting for a
+// receipt, so their final status can be looked up later by tx hash or job ID.
+type txTracker struct {
+	mu        sync.RWMutex
+	txs       map[common.Hash]*TrackedTx
+	jobs      map[string]common.Hash
+	retention time.Duration
+}
+
+var trackedTxs = &txTracker{
+	txs:  make(map[common.Hash]*TrackedTx),
+	jobs: make(map[string]common.Hash),
+}
+
+type confirmJob struct {
+	net    *network
+	tx     *types.Transaction
+	target common.Address
+	amount *big.Int
+}
+
+// confirmQueue feeds broadcast mints to a fixed pool of confirmation
+// workers, so the number of receipts being polled for stays bounded however
+// many mints are accepted.
+var confirmQueue chan confirmJob
+
+func (t *txTracker) add(net *network, tx *types.Transaction, target common.Address, amount string) string {
+	now := time.Now().UTC()
+	jobID := newJobID()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.jobs[jobID] = tx.Hash()
+	t.txs[tx.Hash()] = &TrackedTx{
+		JobID:       jobID,
+		Network:     net.label(),
+		TxHash:      tx.Hash().Hex(),
+		Status:      txStatusPending,
+		Nonce:       tx.Nonce(),
+		Company:     target.Hex(),
+		Amount:      amount,
+		SubmittedAt: now,
+		UpdatedAt:   now,
+	}
+	return jobID
+}
+
+func newJobID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func (t *txTracker) complete(hash common.Hash, result MintResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.txs[hash]
+	if !ok {
+		return
+	}
+	entry.Status = historyStatus(result)
+	entry.Result = &result
+	entry.UpdatedAt = time.Now().UTC()
+}
+
+// prune drops finished entries older than the retention window. Pending
+// entries are kept until their confirmation completes.
+func (t *txTracker) prune() {
+	cutoff := time.Now().Add(-t.retention)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for hash, entry := range t.txs {
+		if entry.Status != txStatusPending && entry.UpdatedAt.Before(cutoff) {
+			delete(t.txs, hash)
+			delete(t.jobs, entry.JobID)
+		}
+	}
+}
+
+func loadTxTrackerConfig() error {
+	retention, err := envDuration("TX_STATUS_RETENTION", time.Hour)
+	if err != nil {
+		return err
+	}
+	if retention <= 0 {
+		return fmt.Errorf("TX_STATUS_RETENTION must be positive")
+	}
+
+	workers, err := envInt("CONFIRM_WORKERS", 4)
+	if err != nil {
+		return err
+	}
+	queueSize, err := envInt("CONFIRM_QUEUE_SIZE", 1000)
+	if err != nil {
+		return err
+	}
+	if workers <= 0 || queueSize <= 0 {
+		return fmt.Errorf("CONFIRM_WORKERS and CONFIRM_QUEUE_SIZE must be positive")
+	}
+
+	trackedTxs.retention = retention
+	go runPruneLoop(retention, trackedTxs.prune)
+
+	confirmQueue = make(chan confirmJob, queueSize)
+	for i := 0; i < workers; i++ {
+		go runConfirmWorker()
+	}
+	return nil
+}
+
+func runConfirmWorker() {
+	for job := range confirmQueue {
+		runConfirmJob(job)
+	}
+}
+
+// enqueueConfirm hands a broadcast mint to the confirmation workers without
+// ever blocking the request that sent it. When the queue is full the job is
+// confirmed on its own goroutine instead; the transaction is already out, so
+// turning the request away at this point would only hide it.
+func enqueueConfirm(job confirmJob) {
+	select {
+	case confirmQueue <- job:
+	default:
+		log.Printf("Confirm queue full (%d jobs); confirming %s outside the worker pool", cap(confirmQueue), job.tx.Hash().Hex())
+		go runConfirmJob(job)
+	}
+}
+
+func runConfirmJob(job confirmJob) {
+	_, result := confirmMint(job.net, job.tx, job.target, job.amount)
+	result.Network = job.net.label()
+	stats.recordOutcome(result.Success)
+	updateMintHistory(job.tx.Hash(), result)
+	trackedTxs.complete(job.tx.Hash(), result)
+	log.Printf("Async mint %s finished: status=%s message=%q", job.tx.Hash().Hex(), historyStatus(result), result.Message)
+}
+
+func (t *txTracker) get(hash common.Hash) (TrackedTx, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	entry, ok := t.txs[hash]
+	if !ok {
+		return TrackedTx{}, false
+	}
+	return *entry, true
+}
+
+func (t *txTracker) getJob(jobID string) (TrackedTx, bool) {
+	t.mu.RLock()
+	hash, ok := t.jobs[jobID]
+	t.mu.RUnlock()
+	if !ok {
+		return TrackedTx{}, false
+	}
+	return t.get(hash)
+}
+
+// mintJobHandler reports the status of an asynchronous mint by the job ID
+// returned from POST /mint.
+func mintJobHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := trackedTxs.getJob(mux.Vars(r)["jobId"])
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Mint job not found")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+// txStatusHandler reports the status of a mint tracked by this instance,
+// falling back to the node (of ?network=, default the primary) for
+// transactions it did not submit or whose confirmation timed out.
+func txStatusHandler(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["hash"]
+	if !isValidTxHash(hash) {
+		respondWithError(w, http.StatusBadRequest, "Invalid transaction hash: expected 0x-prefixed 32-byte hex")
+		return
+	}
+	txHash := common.HexToHash(hash)
+
+	tracked, ok := trackedTxs.get(txHash)
+	if ok && tracked.Status != txStatusUnknown {
+		respondWithJSON(w, http.StatusOK, tracked)
+		return
+	}
+
+	net, err := networkFor(r.URL.Query().Get("network"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	receipt, err := net.client.TransactionReceipt(ctx, txHash)
+	if err == nil {
+		status := txStatusConfirmed
+		if receipt.Status == types.ReceiptStatusFailed {
+			status = txStatusFailed
+		}
+		respondWithJSON(w, http.StatusOK, TrackedTx{
+			TxHash: txHash.Hex(),
+			Status: status,
+			Result: &MintResponse{Success: status == txStatusConfirmed, TxHash: txHash.Hex(), BlockNumber: receipt.BlockNumber.Uint64()},
+		})
+		return
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch transaction receipt: "+err.Error())
+		return
+	}
+
+	if _, pending, err := net.client.TransactionByHash(ctx, txHash); err == nil && pending {
+		respondWithJSON(w, http.StatusOK, TrackedTx{TxHash: txHash.Hex(), Status: txStatusPending})
+		return
+	}
+	if ok {
+		respondWithJSON(w, http.StatusOK, tracked)
+		return
+	}
+
+	respondWithError(w, http.StatusNotFound, "Transaction not found")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMintAsyncDoesNotBlockOnFullQueue(t *testing.T) {
+	net, _ := newMintTestNetwork(t, mintNodeHandlers(), 0)
+
+	// A queue with no room and no workers: a blocking send would hang here.
+	setGlobal(t, &confirmQueue, make(chan confirmJob))
+
+	returned := make(chan MintResponse)
+	go func() {
+		_, resp := mintAsync(net, testRecipient, big.NewInt(100))
+		returned <- resp
+	}()
+
+	select {
+	case resp := <-returned:
+		if !resp.Success || resp.TxHash == "" {
+			t.Fatalf("mintAsync() = %+v", resp)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			entry, _ := trackedTxs.getJob(resp.JobID)
+			if entry.Status == txStatusConfirmed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("overflowed mint still %q", entry.Status)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mintAsync blocked on the full confirm queue")
+	}
+}
+
+func TestWaitForReceiptPollsImmediately(t *testing.T) {
+	hash := common.HexToHash("0xabc")
+	receipt := &types.Receipt{
+		Type:        types.DynamicFeeTxType,
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      hash,
+		BlockHash:   common.HexToHash("0x10"),
+		BlockNumber: big.NewInt(10),
+		Logs:        []*types.Log{},
+	}
+	net, node := newNodeNetwork(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_getTransactionReceipt": func([]json.RawMessage) (any, error) { return receipt, nil },
+		"eth_blockNumber":           func([]json.RawMessage) (any, error) { return "0xc", nil },
+	})
+
+	keepGlobal(t, &requiredConfirmations)
+	for _, confirmations := range []uint64{1, 3} {
+		requiredConfirmations = confirmations
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		got, err := waitForReceipt(ctx, net, hash)
+		cancel()
+		if err != nil {
+			t.Fatalf("confirmations=%d: waitForReceipt() error = %v", confirmations, err)
+		}
+		if got.TxHash != hash {
+			t.Errorf("confirmations=%d: receipt for %s", confirmations, got.TxHash.Hex())
+		}
+	}
+
+	if calls := node.callCount("eth_getTransactionReceipt"); calls != 2 {
+		t.Errorf("receipt polled %d times, want once per wait", calls)
+	}
+}
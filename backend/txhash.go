@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// isValidTxHash reports whether s is a 0x-prefixed, 32-byte hex string.
+func isValidTxHash(s string) bool {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return false
+	}
+	raw := s[2:]
+	if len(raw) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(raw)
+	return err == nil
+}
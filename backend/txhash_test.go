@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidTxHash(t *testing.T) {
+	valid := "0x" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"valid lowercase", valid, true},
+		{"valid uppercase prefix and digits", "0X" + strings.Repeat("AB", 32), true},
+		{"empty", "", false},
+		{"prefix only", "0x", false},
+		{"too short", valid[:len(valid)-2], false},
+		{"too long", valid + "ab", false},
+		{"odd length", valid[:len(valid)-1], false},
+		{"missing prefix", strings.Repeat("ab", 32), false},
+		{"non-hex character", "0x" + strings.Repeat("ab", 31) + "zz", false},
+		{"address instead of hash", "0x1111111111111111111111111111111111111111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidTxHash(tt.input); got != tt.want {
+				t.Errorf("isValidTxHash(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxAmountLength = 100
+
+// decimalPattern is the only syntax parseUnits accepts. big.Rat.SetString
+// also takes Go literals such as "0x10", "0b11", "1_000" and fractions, which
+// must not reach it.
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// parseUnits converts a decimal string such as "12.5" or "1e-18" into an
+// integer amount scaled by 10^decimals. Values that cannot be represented
+// exactly at that precision are rejected rather than rounded.
+func parseUnits(value string, decimals int) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("empty amount")
+	}
+	if len(value) > maxAmountLength || !decimalPattern.MatchString(value) {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	if _, exp, ok := strings.Cut(strings.ToLower(value), "e"); ok {
+		if n, err := strconv.Atoi(exp); err != nil || n > 100 || n < -100 {
+			return nil, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	rat.Mul(rat, new(big.Rat).SetInt(scale))
+	if !rat.IsInt() {
+		return nil, fmt.Errorf("amount %q has more than %d decimal places", value, decimals)
+	}
+	return new(big.Int).Set(rat.Num()), nil
+}
+
+// formatUnits renders an integer amount scaled by 10^decimals as a decimal
+// string, trimming trailing zeros from the fractional part.
+func formatUnits(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return ""
+	}
+
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-decimals]
+	frac := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := whole
+	if frac != "" {
+		result += "." + frac
+	}
+	if amount.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+func formatEther(wei *big.Int) string {
+	return formatUnits(wei, 18)
+}
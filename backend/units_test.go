@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "fractional sales", value: "12.5", want: "12500000000000000000"},
+		{name: "smallest unit", value: "0.000000000000000001", want: "1"},
+		{name: "too many decimals", value: "0.0000000000000000001", wantErr: true},
+		{name: "whole number", value: "1000", want: "1000000000000000000000"},
+		{name: "exponent", value: "1e-18", want: "1"},
+		{name: "positive exponent", value: "1.5E+3", want: "1500000000000000000000"},
+		{name: "surrounding space", value: " 2 ", want: "2000000000000000000"},
+		{name: "zero", value: "0", want: "0"},
+		{name: "exponent too large", value: "1e101", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+		{name: "hex literal", value: "0x10", wantErr: true},
+		{name: "hex with e digit", value: "0x1e", wantErr: true},
+		{name: "binary literal", value: "0b11", wantErr: true},
+		{name: "octal literal", value: "0o17", wantErr: true},
+		{name: "underscore separator", value: "1_000", wantErr: true},
+		{name: "hex float", value: "0x1p-2", wantErr: true},
+		{name: "fraction", value: "1/4", wantErr: true},
+		{name: "negative", value: "-1", wantErr: true},
+		{name: "explicit plus", value: "+1", wantErr: true},
+		{name: "leading dot", value: ".5", wantErr: true},
+		{name: "trailing dot", value: "5.", wantErr: true},
+		{name: "infinity", value: "Inf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUnits(tt.value, 18)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUnits(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("parseUnits(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"12500000000000000000", 18, "12.5"},
+		{"1", 18, "0.000000000000000001"},
+		{"1000000000000000000", 18, "1"},
+		{"0", 18, "0"},
+		{"-1500000", 6, "-1.5"},
+		{"42", 0, "42"},
+	}
+
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.amount, 10)
+		if got := formatUnits(amount, tt.decimals); got != tt.want {
+			t.Errorf("formatUnits(%s, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
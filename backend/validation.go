@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// validationError collects every problem found in a request so clients get
+// a single 400 listing all conflicts instead of fixing them one at a time.
+type validationError struct {
+	problems []string
+}
+
+func (e *validationError) Error() string {
+	return strings.Join(e.problems, "; ")
+}
+
+func (e *validationError) add(problem string) {
+	e.problems = append(e.problems, problem)
+}
+
+// validateMintRequest checks the request as a whole and returns the amount
+// to mint in token base units. The amount is given either as "sales" (whole
+// tokens, scaled by the token's decimals) or as "amountWei" (base units);
+// exactly one of the two must be set.
+func validateMintRequest(req MintRequest) (*big.Int, error) {
+	verr := &validationError{}
+	var amount *big.Int
+
+	switch {
+	case req.Sales != "" && req.AmountWei != "":
+		verr.add("sales and amountWei are mutually exclusive")
+	case req.AmountWei != "":
+		value, ok := new(big.Int).SetString(req.AmountWei, 10)
+		if !ok || value.Sign() <= 0 {
+			verr.add("amountWei must be a positive base-10 integer")
+		} else {
+			amount = value
+		}
+	case req.Sales == "":
+		verr.add("Sales amount must be positive")
+	default:
+		value, err := salesToAmount(req.Sales)
+		switch {
+		case err != nil:
+			verr.add(fmt.Sprintf("Invalid sales amount: %v", err))
+		case value.Sign() <= 0:
+			verr.add("Sales amount must be positive")
+		default:
+			amount = value
+		}
+	}
+
+	if amount != nil && amount.Cmp(maxUint256) > 0 {
+		verr.add("Mint amount exceeds the uint256 maximum")
+	}
+
+	if !common.IsHexAddress(req.Company) {
+		verr.add("Invalid Ethereum address")
+	}
+
+	if len(verr.problems) > 0 {
+		return nil, verr
+	}
+	return amount, nil
+}
+
+// decimalAmount holds a sales figure exactly as the client wrote it. It
+// accepts either a JSON number or a decimal string, so values like 12.5 or
+// "0.000000000000000001" are never routed through a float64.
+type decimalAmount string
+
+func (d *decimalAmount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = decimalAmount(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*d = decimalAmount(n.String())
+	return nil
+}
+
+// salesToAmount scales a sales figure to token base units using the token's
+// decimals, rejecting values with more decimal places than the token has.
+func salesToAmount(sales decimalAmount) (*big.Int, error) {
+	return parseUnits(string(sales), tokenDecimals)
+}
+
+func respondWithValidationError(w http.ResponseWriter, err error) {
+	var verr *validationError
+	if errors.As(err, &verr) && len(verr.problems) > 1 {
+		respondWithJSON(w, http.StatusBadRequest, MintResponse{
+			Message: "Invalid request: " + verr.Error(),
+			Errors:  verr.problems,
+		})
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateMintRequestLargeAmounts(t *testing.T) {
+	const company = "0x1111111111111111111111111111111111111111"
+	setGlobal(t, &tokenDecimals, 18)
+
+	tests := []struct {
+		name    string
+		req     MintRequest
+		wantErr string
+	}{
+		{
+			name:    "sales in exponent form",
+			req:     MintRequest{Company: company, Sales: "1e100"},
+			wantErr: "Mint amount exceeds the uint256 maximum",
+		},
+		{
+			name:    "sales with 99 digits",
+			req:     MintRequest{Company: company, Sales: decimalAmount(strings.Repeat("9", 99))},
+			wantErr: "Mint amount exceeds the uint256 maximum",
+		},
+		{
+			name:    "sales exponent beyond the parser limit",
+			req:     MintRequest{Company: company, Sales: "1e1000000"},
+			wantErr: "Invalid sales amount",
+		},
+		{
+			name:    "amountWei one above uint256 max",
+			req:     MintRequest{Company: company, AmountWei: "115792089237316195423570985008687907853269984665640564039457584007913129639936"},
+			wantErr: "Mint amount exceeds the uint256 maximum",
+		},
+		{
+			name: "amountWei at uint256 max",
+			req:  MintRequest{Company: company, AmountWei: maxUint256.String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := validateMintRequest(tt.req)
+			if tt.wantErr == "" {
+				if err != nil || amount.Cmp(maxUint256) != 0 {
+					t.Fatalf("validateMintRequest() = %v, %v; want uint256 max", amount, err)
+				}
+				return
+			}
+
+			var verr *validationError
+			if !errors.As(err, &verr) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateMintRequest() error = %v, want %q", err, tt.wantErr)
+			}
+			if amount != nil {
+				t.Errorf("amount = %s, want nil", amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var verifyBalanceDelta bool
+
+// observedBalanceDelta compares the target's token balance at the mint's
+// block with its balance at the parent block. Other transfers to or from
+// the target in the same block are included in the delta, so a mismatch is
+// a signal to investigate rather than proof of a contract bug.
+func observedBalanceDelta(net *network, target common.Address, blockNumber *big.Int) (*big.Int, error) {
+	ctx := context.Background()
+
+	before, err := net.contract.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).Sub(blockNumber, big.NewInt(1))}, target)
+	if err != nil {
+		return nil, err
+	}
+	after, err := net.contract.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber}, target)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(after, before), nil
+}
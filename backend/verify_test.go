@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// balanceNode answers balanceOf calls with the balance at each block.
+func balanceNode(balances map[int64]int64) map[string]func(params []json.RawMessage) (any, error) {
+	return map[string]func(params []json.RawMessage) (any, error){
+		"eth_call": func(params []json.RawMessage) (any, error) {
+			block := callBlock(params)
+			if block == nil {
+				return encodeUint256(big.NewInt(0)), nil
+			}
+			return encodeUint256(big.NewInt(balances[block.Int64()])), nil
+		},
+	}
+}
+
+func TestObservedBalanceDelta(t *testing.T) {
+	net, _ := newNodeNetwork(t, balanceNode(map[int64]int64{9: 50, 10: 140}))
+
+	delta, err := observedBalanceDelta(net, testRecipient, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.Int64() != 90 {
+		t.Errorf("observedBalanceDelta() = %s, want 90", delta)
+	}
+}
+
+func TestConfirmMintBalanceDelta(t *testing.T) {
+	setGlobal(t, &verifyBalanceDelta, true)
+
+	tests := []struct {
+		name         string
+		balanceAfter int64
+		wantDelta    string
+		wantMismatch bool
+	}{
+		{name: "delta matches", balanceAfter: 150, wantDelta: "100"},
+		{name: "delta differs", balanceAfter: 140, wantDelta: "90", wantMismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, _ := newNodeNetwork(t, balanceNode(map[int64]int64{9: 50, 10: tt.balanceAfter}))
+			net.broadcaster = &stubBroadcaster{receipt: func(tx *types.Transaction) (*types.Receipt, error) {
+				return &types.Receipt{
+					Status:      types.ReceiptStatusSuccessful,
+					TxHash:      tx.Hash(),
+					BlockNumber: big.NewInt(10),
+					Logs:        []*types.Log{transferLog(t, testContractAddr, common.Address{}, testRecipient, 100)},
+				}, nil
+			}}
+			tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &testContractAddr, Gas: 100000, GasPrice: big.NewInt(1)})
+
+			_, resp := confirmMint(net, tx, testRecipient, big.NewInt(100))
+			if !resp.Success {
+				t.Fatalf("confirmMint() failed: %s", resp.Message)
+			}
+			if resp.BalanceDelta != tt.wantDelta || resp.BalanceDeltaMismatch != tt.wantMismatch {
+				t.Errorf("balanceDelta = %s, mismatch = %v; want %s, %v", resp.BalanceDelta, resp.BalanceDeltaMismatch, tt.wantDelta, tt.wantMismatch)
+			}
+		})
+	}
+}